@@ -0,0 +1,11 @@
+data "cloudfoundry_app_logs" "http-bin-server" {
+  name         = "tf-test-do-not-delete-http-bin"
+  space_name   = "tf-space-1"
+  org_name     = "PerformanceTeamBLR"
+  source_types = ["STG", "APP"]
+  limit        = 50
+}
+
+output "logs" {
+  value = [for l in data.cloudfoundry_app_logs.http-bin-server.logs : "${l.timestamp} [${l.source_type}/${l.instance_id}] ${l.message}"]
+}
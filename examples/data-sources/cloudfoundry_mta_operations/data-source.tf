@@ -0,0 +1,21 @@
+data "cloudfoundry_mta_operations" "running" {
+  space  = "02c0cc92-6ecc-44b1-b7b2-096ca19ee143"
+  mta_id = "a.cf.app"
+  states = ["RUNNING", "ACTION_REQUIRED"]
+}
+
+data "cloudfoundry_mta_operations" "history" {
+  space = "02c0cc92-6ecc-44b1-b7b2-096ca19ee143"
+  last  = 10
+}
+
+output "deployments" {
+  value = [for op in data.cloudfoundry_mta_operations.history.operations : "${op.started_at} ${op.process_type} ${op.mta_id} by ${op.user}: ${op.state}"]
+}
+
+check "no_running_deployment" {
+  assert {
+    condition     = length(data.cloudfoundry_mta_operations.running.operations) == 0
+    error_message = "Another operation is still running on MTA a.cf.app."
+  }
+}
@@ -28,6 +28,14 @@ resource "cloudfoundry_app" "gobis-server" {
     MY_ENV = "red",
   }
   strategy = "rolling"
+  wait_for = {
+    min_running_instances = { web = 1 }
+    timeout               = 300
+    smoke_check = {
+      path            = "/"
+      expected_status = 200
+    }
+  }
   service_bindings = [
     {
       service_instance : "xsuaa-tf"
@@ -105,4 +113,65 @@ resource "cloudfoundry_app" "http-bin-sidecar" {
     }
   ]
   no_route = true
-}
\ No newline at end of file
+}
+
+resource "cloudfoundry_app" "nodejs-cnb" {
+  name           = "tf-test-nodejs-cnb"
+  space_name     = "tf-space-1"
+  org_name       = "PerformanceTeamBLR"
+  path           = zipper_file.fixture.output_path
+  lifecycle_type = "cnb"
+  buildpacks     = ["docker://registry.example.com/paketobuildpacks/nodejs:latest"]
+  cnb_credentials = jsonencode({
+    "registry.example.com" = {
+      username = "user"
+      password = "secret"
+    }
+  })
+}
+
+resource "cloudfoundry_app" "nodejs-manifest" {
+  name          = "tf-test-nodejs-manifest"
+  space_name    = "tf-space-1"
+  org_name      = "PerformanceTeamBLR"
+  manifest_path = "${path.module}/manifest.yml"
+  vars_files    = ["${path.module}/vars-dev.yml"]
+  vars = {
+    instances = "2"
+  }
+  # overrides the memory of the manifest
+  memory = "512M"
+}
+
+resource "cloudfoundry_app" "standby" {
+  name       = "tf-test-nodejs-standby"
+  space_name = "tf-space-1"
+  org_name   = "PerformanceTeamBLR"
+  path       = zipper_file.fixture.output_path
+  state      = "STOPPED"
+}
+
+resource "cloudfoundry_app" "restarted-on-rotation" {
+  name       = "tf-test-nodejs-rotation"
+  space_name = "tf-space-1"
+  org_name   = "PerformanceTeamBLR"
+  path       = zipper_file.fixture.output_path
+  strategy   = "rolling"
+  restart_triggers = {
+    credentials = filesha256("${path.module}/credentials.json")
+  }
+}
+
+# changing space_name pushes the app to the new space, moves its routes and deletes the old app afterwards
+resource "cloudfoundry_app" "movable" {
+  name                     = "tf-test-nodejs-movable"
+  space_name               = "tf-space-2"
+  org_name                 = "PerformanceTeamBLR"
+  path                     = zipper_file.fixture.output_path
+  zero_downtime_space_move = true
+  routes = [
+    {
+      route = "tf-test-nodejs-movable.cfapps.sap.hana.ondemand.com"
+    }
+  ]
+}
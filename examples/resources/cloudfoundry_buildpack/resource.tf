@@ -6,4 +6,11 @@ resource "cloudfoundry_buildpack" "mybuildpack" {
   locked   = false
   labels   = { "hi" : "fi" }
   path     = "somethin.zip"
-} 
\ No newline at end of file
+} 
+
+resource "cloudfoundry_buildpack" "mycnb" {
+  name           = "nodejs-cnb"
+  lifecycle_type = "cnb"
+  stack          = "cflinuxfs4"
+  path           = "nodejs-cnb.cnb"
+}
@@ -5,3 +5,61 @@ resource "cloudfoundry_mta" "mtar" {
   namespace             = "test"
   source_code_hash      = join("", [filesha256("./my-mta_1.0.0.mtar"), filesha256("./prod.mtaext"), filesha256("prod-scale-vertically.mtaext")])
 }
+
+resource "cloudfoundry_mta" "blue_green" {
+  space            = "02c0cc92-6ecc-44b1-b7b2-096ca19ee143"
+  mtar_path        = "./my-mta_1.0.0.mtar"
+  source_code_hash = filesha256("./my-mta_1.0.0.mtar")
+  deploy_strategy  = "blue-green"
+  logs_directory   = "${path.root}/mta-logs"
+  blue_green = {
+    smoke_check = {
+      url           = "https://my-app-idle.cfapps.sap.hana.ondemand.com/health"
+      expected_body = "UP"
+    }
+  }
+  timeouts = {
+    create = "30m"
+    update = "30m"
+  }
+}
+
+resource "cloudfoundry_mta" "partial" {
+  space            = "02c0cc92-6ecc-44b1-b7b2-096ca19ee143"
+  mtar_path        = "./my-mta_1.0.0.mtar"
+  source_code_hash = filesha256("./my-mta_1.0.0.mtar")
+  modules          = ["my-mta-managed-app-module"]
+  version_rule     = "ALL"
+  keep_files       = true
+  abort_on_error   = true
+  undeploy = {
+    delete_services     = true
+    delete_service_keys = true
+  }
+}
+
+resource "cloudfoundry_mta" "from_directory" {
+  space         = "02c0cc92-6ecc-44b1-b7b2-096ca19ee143"
+  mta_directory = "./my-mta"
+}
+
+resource "cloudfoundry_mta" "inline_extension" {
+  space            = "02c0cc92-6ecc-44b1-b7b2-096ca19ee143"
+  mtar_path        = "./my-mta_1.0.0.mtar"
+  source_code_hash = filesha256("./my-mta_1.0.0.mtar")
+  extension_descriptor_content = [
+    yamlencode({
+      "_schema-version" = "3.3.0"
+      ID                = "my-mta-dev"
+      extends           = "my-mta"
+      modules = [{
+        name       = "my-app"
+        parameters = { instances = 1 }
+      }]
+    })
+  ]
+}
+
+output "mta_added_modules" {
+  value = cloudfoundry_mta.mtar.deployment_preview.added_modules
+}
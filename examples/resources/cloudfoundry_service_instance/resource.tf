@@ -66,3 +66,12 @@ resource "cloudfoundry_service_instance" "dev-usp" {
   }
   EOT
 }
+
+# Managed service instance with the service plan resolved by name
+resource "cloudfoundry_service_instance" "dev-redis" {
+  name             = "tf-redis-test"
+  type             = "managed"
+  space            = data.cloudfoundry_space.team_space.id
+  service_offering = "redis-cache"
+  plan_name        = "trial"
+}
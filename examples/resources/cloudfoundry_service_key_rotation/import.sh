@@ -0,0 +1 @@
+terraform import cloudfoundry_service_key_rotation.xsuaa e9ec29ca-993d-42e2-9c5b-cb17b1972cce/xsuaa-key
\ No newline at end of file
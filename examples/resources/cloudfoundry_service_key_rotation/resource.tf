@@ -0,0 +1,18 @@
+resource "cloudfoundry_service_key_rotation" "xsuaa" {
+  name             = "xsuaa-key"
+  service_instance = "e9ec29ca-993d-42e2-9c5b-cb17b1972cce"
+  generations      = 2
+  rotation_period  = "720h"
+  overlap          = "24h"
+}
+
+resource "cloudfoundry_service_key_rotation" "xsuaa_manual" {
+  name             = "xsuaa-manual-key"
+  service_instance = "e9ec29ca-993d-42e2-9c5b-cb17b1972cce"
+  rotation_trigger = "2024-07-01"
+  parameters = jsonencode({
+    "xsuaa" : {
+      "credential-types" : ["x509"]
+    }
+  })
+}
@@ -1,10 +1,12 @@
 package mta
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
@@ -33,7 +35,7 @@ type Request struct {
 	queryParams url.Values
 	formParams  url.Values
 	fileName    string
-	fileBytes   []byte
+	fileContent *io.SectionReader
 }
 
 func newRequestInfo() Request {
@@ -106,6 +108,32 @@ func (a *DefaultApiService) GetMtaOperation(ctx context.Context, spaceGuid strin
 	return operation, httpResponse, err
 }
 
+/*
+Retrieves the logs of a Multi-Target Application operation.
+*/
+func (a *DefaultApiService) GetMtaOperationLogs(ctx context.Context, spaceGuid string, operationId string) ([]Log, *http.Response, error) {
+	var (
+		logs    []Log
+		request Request = newRequestInfo()
+	)
+	request.path = a.client.cfg.BasePath + "/api/v1/spaces/" + spaceGuid + "/operations/" + operationId + "/logs"
+	httpResponse, err := a.client.get(ctx, request, &logs)
+	return logs, httpResponse, err
+}
+
+/*
+Retrieves the content of a Multi-Target Application operation log.
+*/
+func (a *DefaultApiService) GetMtaOperationLogContent(ctx context.Context, spaceGuid string, operationId string, logId string) (string, *http.Response, error) {
+	var (
+		content string
+		request Request = newRequestInfo()
+	)
+	request.path = a.client.cfg.BasePath + "/api/v1/spaces/" + spaceGuid + "/operations/" + operationId + "/logs/" + logId + "/content"
+	httpResponse, err := a.client.get(ctx, request, &content)
+	return content, httpResponse, err
+}
+
 /*
 Retrieves Multi-Target Application operations.
 */
@@ -120,7 +148,7 @@ func (a *DefaultApiService) GetMtaOperations(ctx context.Context, spaceGuid stri
 			request.queryParams.Add("mtaId", *localVarOptionals.MtaId)
 		}
 		if localVarOptionals.Last != nil {
-			request.queryParams.Add("last", parameterToString(localVarOptionals.Last, ""))
+			request.queryParams.Add("last", parameterToString(*localVarOptionals.Last, ""))
 		}
 		if localVarOptionals.State != nil {
 			request.queryParams.Add("state", parameterToString(localVarOptionals.State, "csv"))
@@ -167,20 +195,38 @@ func (a *DefaultApiService) StartMtaOperation(ctx context.Context, spaceGuid str
 Uploads an Multi Target Application archive or an Extension Descriptor.
 */
 func (a *DefaultApiService) UploadMtaFile(ctx context.Context, spaceGuid string, namespace string, filePath string) (FileMetadata, *http.Response, error) {
+	if filePath == "" {
+		return FileMetadata{}, nil, errors.New("filePath required for uploading")
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return FileMetadata{}, nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return FileMetadata{}, nil, err
+	}
+	return a.UploadMtaFilePart(ctx, spaceGuid, namespace, filepath.Base(filePath), io.NewSectionReader(file, 0, info.Size()))
+}
+
+/*
+Uploads a Multi Target Application file from memory.
+*/
+func (a *DefaultApiService) UploadMtaFileContent(ctx context.Context, spaceGuid string, namespace string, fileName string, fileBytes []byte) (FileMetadata, *http.Response, error) {
+	return a.UploadMtaFilePart(ctx, spaceGuid, namespace, fileName, io.NewSectionReader(bytes.NewReader(fileBytes), 0, int64(len(fileBytes))))
+}
+
+/*
+Uploads a section of a file, the content is streamed to the deploy service.
+*/
+func (a *DefaultApiService) UploadMtaFilePart(ctx context.Context, spaceGuid string, namespace string, fileName string, content *io.SectionReader) (FileMetadata, *http.Response, error) {
 	var (
 		file    FileMetadata
-		err     error
 		request Request = newRequestInfo()
 	)
-	if filePath != "" {
-		request.fileBytes, err = os.ReadFile(filePath)
-		if err != nil {
-			return file, nil, err
-		}
-		request.fileName = filepath.Base(filePath)
-	} else {
-		return file, nil, errors.New("filePath required for uploading")
-	}
+	request.fileContent = content
+	request.fileName = fileName
 	request.path = a.client.cfg.BasePath + "/api/v1/spaces/" + spaceGuid + "/files"
 	if namespace != "" {
 		request.queryParams.Add("namespace", namespace)
@@ -9,22 +9,27 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
 	"path/filepath"
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
 )
 
 var (
 	jsonCheck = regexp.MustCompile("(?i:(?:application|text)/json)")
 )
 
+const csrfTokenHeader = "X-Csrf-Token"
+
 // APIClient manages communication with the MTA REST API API v1.3.0
-// In most cases there should be only one, shared, APIClient.
+// The configuration of a client is not changed after creation, use WithBasePath to talk to a different deploy service.
 type APIClient struct {
-	cfg    *Configuration
-	common service // Reuse a single struct instead of allocating one for each service on the heap.
+	cfg     *Configuration
+	session *session
+	common  service // Reuse a single struct instead of allocating one for each service on the heap.
 	// API Services
 	DefaultApi *DefaultApiService
 }
@@ -33,15 +38,32 @@ type service struct {
 	client *APIClient
 }
 
+// session holds the CSRF tokens of the deploy services the client has talked to, keyed by base path.
+// The cookies belonging to the tokens are kept in the cookie jar of the HTTP client.
+type session struct {
+	mu         sync.Mutex
+	csrfTokens map[string]string
+}
+
 // NewAPIClient creates a new API client. Requires a userAgent string describing your application.
 // optionally a custom http.Client to allow for advanced features such as caching.
 func NewAPIClient(cfg *Configuration) *APIClient {
 	if cfg.HTTPClient == nil {
 		cfg.HTTPClient = http.DefaultClient
 	}
+	// Keep the cookies of the deploy service session on a copy of the client,
+	// the given client may be shared with other APIs.
+	httpClient := *cfg.HTTPClient
+	httpClient.Jar, _ = cookiejar.New(nil)
+	cfg.HTTPClient = &httpClient
+
+	return newAPIClient(cfg, &session{csrfTokens: map[string]string{}})
+}
 
+func newAPIClient(cfg *Configuration, session *session) *APIClient {
 	c := &APIClient{}
 	c.cfg = cfg
+	c.session = session
 	c.common.client = c
 
 	// API Services
@@ -67,25 +89,69 @@ func parameterToString(obj interface{}, collectionFormat string) string {
 }
 
 // callAPI do the request.
-func (c *APIClient) callAPI(request *http.Request) (resp *http.Response, err error) {
-	if request.Method == "POST" {
-		resp, err = c.DefaultApi.GetCsrfToken(request.Context())
-		if err != nil {
-			return resp, err
+func (c *APIClient) callAPI(request *http.Request) (*http.Response, error) {
+	if request.Method != POST {
+		return c.cfg.HTTPClient.Do(request)
+	}
+	token, err := c.csrfToken(request.Context(), false)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set(csrfTokenHeader, token)
+	resp, err := c.cfg.HTTPClient.Do(request)
+	if err != nil || !csrfTokenRequired(resp) || (request.Body != nil && request.GetBody == nil) {
+		return resp, err
+	}
+
+	// The token is no longer valid, e.g. because the session expired. Fetch a new one and retry once.
+	resp.Body.Close()
+	retry := request.Clone(request.Context())
+	if request.GetBody != nil {
+		if retry.Body, err = request.GetBody(); err != nil {
+			return nil, err
 		}
-		csrfToken := resp.Header.Values("x-csrf-token")[0]
-		cookies := resp.Cookies()
-		cookieValue := cookies[0].Name + "=" + cookies[0].Value + "; " + cookies[1].Name + "=" + cookies[1].Value
-		request.Header.Add("x-csrf-token", csrfToken)
-		request.Header.Add("Cookie", cookieValue)
-	}
-	resp, err = c.cfg.HTTPClient.Do(request)
-	return resp, err
+	}
+	if token, err = c.csrfToken(request.Context(), true); err != nil {
+		return nil, err
+	}
+	retry.Header.Set(csrfTokenHeader, token)
+	return c.cfg.HTTPClient.Do(retry)
+}
+
+// csrfToken returns the cached CSRF token of the session or fetches a new one.
+func (c *APIClient) csrfToken(ctx context.Context, refresh bool) (string, error) {
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+
+	if token, ok := c.session.csrfTokens[c.cfg.BasePath]; ok && !refresh {
+		return token, nil
+	}
+	resp, err := c.DefaultApi.GetCsrfToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	token := resp.Header.Get(csrfTokenHeader)
+	if token == "" {
+		return "", GenericError{
+			error: fmt.Sprintf("no %s header in the response of the deploy service", csrfTokenHeader),
+		}
+	}
+	c.session.csrfTokens[c.cfg.BasePath] = token
+	return token, nil
+}
+
+// csrfTokenRequired reports whether the deploy service rejected a request because of a missing or invalid CSRF token.
+func csrfTokenRequired(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusForbidden && strings.EqualFold(resp.Header.Get(csrfTokenHeader), "required")
 }
 
-// Change base path to allow switching to mocks.
-func (c *APIClient) ChangeBasePath(path string) {
-	c.cfg.BasePath = path
+// WithBasePath returns a new client for the given base path. The configuration of the
+// receiver is left untouched, so clients for different deploy services can be used concurrently.
+// The returned client shares the cookies and CSRF tokens of the receiver.
+func (c *APIClient) WithBasePath(path string) *APIClient {
+	cfg := c.cfg.clone()
+	cfg.BasePath = path
+	return newAPIClient(cfg, c.session)
 }
 
 // prepareRequest build the request.
@@ -97,7 +163,7 @@ func (c *APIClient) prepareRequest(
 	queryParams url.Values,
 	formParams url.Values,
 	fileName string,
-	fileBytes []byte) (localVarRequest *http.Request, err error) {
+	fileContent *io.SectionReader) (localVarRequest *http.Request, err error) {
 
 	var body *bytes.Buffer
 
@@ -115,32 +181,35 @@ func (c *APIClient) prepareRequest(
 		}
 	}
 
-	// add form parameters and file if available.
-	if strings.HasPrefix(headerParams["Content-Type"], "multipart/form-data") && len(formParams) > 0 || (len(fileBytes) > 0 && fileName != "") {
+	// add form parameters if available.
+	if strings.HasPrefix(headerParams["Content-Type"], "multipart/form-data") && len(formParams) > 0 && fileContent == nil {
 		if body != nil {
 			return nil, errors.New("Cannot specify postBody and multipart form at the same time.")
 		}
 		body = &bytes.Buffer{}
 		w := multipart.NewWriter(body)
-
-		if len(fileBytes) > 0 && fileName != "" {
-			w.Boundary()
-			//_, fileNm := filepath.Split(fileName)
-			part, err := w.CreateFormFile("file", filepath.Base(fileName))
-			if err != nil {
-				return nil, err
-			}
-			_, err = part.Write(fileBytes)
-			if err != nil {
-				return nil, err
+		for k, v := range formParams {
+			for _, iv := range v {
+				if err = w.WriteField(k, iv); err != nil {
+					return nil, err
+				}
 			}
-			// Set the Boundary in the Content-Type
-			headerParams["Content-Type"] = w.FormDataContentType()
 		}
-
-		// Set Content-Length
-		headerParams["Content-Length"] = fmt.Sprintf("%d", body.Len())
 		w.Close()
+		headerParams["Content-Type"] = w.FormDataContentType()
+	}
+
+	// add the file as a streamed multipart form, so that large files are not kept in memory.
+	var fileBody *multipartFileBody
+	if fileContent != nil && fileName != "" {
+		if body != nil {
+			return nil, errors.New("Cannot specify postBody and multipart form at the same time.")
+		}
+		fileBody, err = newMultipartFileBody(fileName, fileContent)
+		if err != nil {
+			return nil, err
+		}
+		headerParams["Content-Type"] = fileBody.contentType
 	}
 
 	// Setup path and query parameters
@@ -169,6 +238,11 @@ func (c *APIClient) prepareRequest(
 	if err != nil {
 		return nil, err
 	}
+	if fileBody != nil {
+		localVarRequest.Body, _ = fileBody.open()
+		localVarRequest.GetBody = fileBody.open
+		localVarRequest.ContentLength = fileBody.length
+	}
 
 	// add header parameters, if any
 	if len(headerParams) > 0 {
@@ -206,9 +280,28 @@ func (c *APIClient) decode(v interface{}, b []byte, contentType string) (err err
 		}
 		return nil
 	}
+	if strings.Contains(contentType, "text/plain") && setTextValue(v, b) {
+		return nil
+	}
 	return errors.New("undefined response type")
 }
 
+// setTextValue stores a plain text response body in the string v points to.
+func setTextValue(v interface{}, b []byte) bool {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return false
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.String || !rv.CanSet() {
+		return false
+	}
+	rv.SetString(string(b))
+	return true
+}
+
 func (c *APIClient) returnResponse(resp *http.Response, returnValue interface{}, varBody []byte) error {
 	if resp.StatusCode == 204 {
 		return nil
@@ -226,7 +319,7 @@ func (c *APIClient) returnResponse(resp *http.Response, returnValue interface{},
 }
 
 func (c *APIClient) sendRequestGetResponse(ctx context.Context, request Request, returnValue any) (operationId string, localVarHttpResponse *http.Response, err error) {
-	r, err := c.prepareRequest(ctx, request.path, request.method, request.postBody, request.headers, request.queryParams, request.formParams, request.fileName, request.fileBytes)
+	r, err := c.prepareRequest(ctx, request.path, request.method, request.postBody, request.headers, request.queryParams, request.formParams, request.fileName, request.fileContent)
 	if err != nil {
 		return operationId, nil, err
 	}
@@ -300,6 +393,55 @@ func detectContentType(body interface{}) string {
 	return contentType
 }
 
+// multipartFileBody streams a file as the only part of a multipart form.
+type multipartFileBody struct {
+	fileName    string
+	content     *io.SectionReader
+	boundary    string
+	contentType string
+	length      int64
+}
+
+func newMultipartFileBody(fileName string, content *io.SectionReader) (*multipartFileBody, error) {
+	// Write the form without the content of the file to determine the boundary and the overhead of the form.
+	var envelope bytes.Buffer
+	w := multipart.NewWriter(&envelope)
+	if _, err := w.CreateFormFile("file", filepath.Base(fileName)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return &multipartFileBody{
+		fileName:    fileName,
+		content:     content,
+		boundary:    w.Boundary(),
+		contentType: w.FormDataContentType(),
+		length:      int64(envelope.Len()) + content.Size(),
+	}, nil
+}
+
+// open returns a new reader of the form, it can be called again to retry a request.
+func (b *multipartFileBody) open() (io.ReadCloser, error) {
+	reader, writer := io.Pipe()
+	go func() {
+		w := multipart.NewWriter(writer)
+		err := w.SetBoundary(b.boundary)
+		var part io.Writer
+		if err == nil {
+			part, err = w.CreateFormFile("file", filepath.Base(b.fileName))
+		}
+		if err == nil {
+			_, err = io.Copy(part, io.NewSectionReader(b.content, 0, b.content.Size()))
+		}
+		if err == nil {
+			err = w.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+	return reader, nil
+}
+
 // GenericError Provides access to the body, error and model on returned errors.
 type GenericError struct {
 	body  []byte
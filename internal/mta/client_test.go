@@ -0,0 +1,180 @@
+package mta
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testSpaceGuid = "02c0cc92-6ecc-44b1-b7b2-096ca19ee143"
+
+// fakeCsrfService is a deploy service which hands out a new CSRF token on every fetch
+// and rejects operations as long as reject returns true for the token they were sent with.
+type fakeCsrfService struct {
+	mu          sync.Mutex
+	tokens      int
+	posts       []string
+	processType []string
+	reject      func(token string) bool
+}
+
+func (f *fakeCsrfService) start(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/csrf-token", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.tokens++
+		w.Header().Set(csrfTokenHeader, fmt.Sprintf("token-%d", f.tokens))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /api/v1/spaces/{space}/operations", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("[]"))
+	})
+	mux.HandleFunc("POST /api/v1/spaces/{space}/operations", func(w http.ResponseWriter, r *http.Request) {
+		var operation Operation
+		_ = json.NewDecoder(r.Body).Decode(&operation)
+		token := r.Header.Get(csrfTokenHeader)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.posts = append(f.posts, token)
+		f.processType = append(f.processType, operation.ProcessType)
+		if f.reject != nil && f.reject(token) {
+			w.Header().Set(csrfTokenHeader, "Required")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Location", "/api/v1/spaces/"+r.PathValue("space")+"/operations/1")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAPIClient_CsrfTokenCached(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	fake := &fakeCsrfService{}
+	server := fake.start(t)
+	other := &fakeCsrfService{}
+	otherServer := other.start(t)
+	client := NewAPIClient(NewConfiguration(server.URL, "test", nil))
+
+	if _, _, err := client.DefaultApi.GetMtaOperations(ctx, testSpaceGuid, &DefaultApiGetMtaOperationsOpts{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*APIClient{client, client, client.WithBasePath(server.URL)} {
+		if _, _, _, err := c.DefaultApi.StartMtaOperation(ctx, testSpaceGuid, Operation{ProcessType: "DEPLOY"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, _, err := client.WithBasePath(otherServer.URL).DefaultApi.StartMtaOperation(ctx, testSpaceGuid, Operation{ProcessType: "DEPLOY"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if fake.tokens != 1 {
+		t.Errorf("fetched %d CSRF tokens, want 1 for all requests to the same deploy service", fake.tokens)
+	}
+	for _, token := range fake.posts {
+		if token != "token-1" {
+			t.Errorf("operation started with CSRF token %q, want token-1", token)
+		}
+	}
+	if other.tokens != 1 || len(other.posts) != 1 || other.posts[0] != "token-1" {
+		t.Errorf("other deploy service fetched %d tokens and received %v, want its own token", other.tokens, other.posts)
+	}
+}
+
+func TestAPIClient_CsrfTokenRetry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tests := []struct {
+		name       string
+		reject     func(token string) bool
+		wantErr    bool
+		wantTokens []string
+	}{
+		{
+			name:       "expired token is refreshed",
+			reject:     func(token string) bool { return token == "token-1" },
+			wantTokens: []string{"token-1", "token-2"},
+		},
+		{
+			name:       "retried only once",
+			reject:     func(token string) bool { return true },
+			wantErr:    true,
+			wantTokens: []string{"token-1", "token-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fake := &fakeCsrfService{reject: tt.reject}
+			server := fake.start(t)
+			client := NewAPIClient(NewConfiguration(server.URL, "test", nil))
+
+			operationId, _, _, err := client.DefaultApi.StartMtaOperation(ctx, testSpaceGuid, Operation{ProcessType: "UNDEPLOY"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StartMtaOperation() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && operationId != "1" {
+				t.Errorf("StartMtaOperation() = %q, want 1", operationId)
+			}
+			if fmt.Sprint(fake.posts) != fmt.Sprint(tt.wantTokens) {
+				t.Errorf("operations started with CSRF tokens %v, want %v", fake.posts, tt.wantTokens)
+			}
+			for _, processType := range fake.processType {
+				if processType != "UNDEPLOY" {
+					t.Errorf("retried operation has process type %q, want the body of the first request", processType)
+				}
+			}
+		})
+	}
+}
+
+func TestMultipartFileBody(t *testing.T) {
+	t.Parallel()
+	archive := strings.NewReader("0123456789")
+	body, err := newMultipartFileBody("dir/my-mta.mtar.part.1", io.NewSectionReader(archive, 4, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, params, err := mime.ParseMediaType(body.contentType)
+	if err != nil || params["boundary"] != body.boundary {
+		t.Fatalf("content type %q does not carry the boundary %q: %v", body.contentType, body.boundary, err)
+	}
+	// The form can be read again for a retry and is the same every time
+	for range 2 {
+		reader, err := body.open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		form, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(form)) != body.length {
+			t.Errorf("form has %d bytes, want the announced length %d", len(form), body.length)
+		}
+		part, err := multipart.NewReader(bytes.NewReader(form), body.boundary).NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(part)
+		if part.FormName() != "file" || part.FileName() != "my-mta.mtar.part.1" || string(content) != "456" {
+			t.Errorf("form part %s has file %s with content %q, want file my-mta.mtar.part.1 with 456", part.FormName(), part.FileName(), content)
+		}
+	}
+}
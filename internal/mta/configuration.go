@@ -1,9 +1,17 @@
 package mta
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
 	"net/http"
+	"strings"
 )
 
+// deployServiceLink is the name of the link to the deploy service in the root of the Cloud Foundry API.
+const deployServiceLink = "deploy_service"
+
 type Configuration struct {
 	BasePath      string            `json:"basePath,omitempty"`
 	Host          string            `json:"host,omitempty"`
@@ -26,3 +34,41 @@ func NewConfiguration(basePath string, userAgent string, client *http.Client) *C
 func (c *Configuration) AddDefaultHeader(key string, value string) {
 	c.DefaultHeader[key] = value
 }
+
+// clone returns a copy of the configuration that does not share the default headers.
+func (c *Configuration) clone() *Configuration {
+	cfg := *c
+	cfg.DefaultHeader = maps.Clone(c.DefaultHeader)
+	return &cfg
+}
+
+// DiscoverDeployServiceURL reads the URL of the deploy service from the deploy_service link advertised in the
+// root of the Cloud Foundry API at apiURL. It fails if the landscape does not advertise the link.
+func DiscoverDeployServiceURL(ctx context.Context, client *http.Client, apiURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(apiURL, "/")+"/", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("reading the root of the Cloud Foundry API %s failed with status %s", apiURL, resp.Status)
+	}
+
+	var root struct {
+		Links map[string]*struct {
+			Href string `json:"href"`
+		} `json:"links"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&root); err != nil {
+		return "", fmt.Errorf("reading the root of the Cloud Foundry API %s failed with %w", apiURL, err)
+	}
+	link := root.Links[deployServiceLink]
+	if link == nil || link.Href == "" {
+		return "", fmt.Errorf("the Cloud Foundry API %s does not advertise a %s link", apiURL, deployServiceLink)
+	}
+	return strings.TrimSuffix(link.Href, "/"), nil
+}
@@ -0,0 +1,78 @@
+package mta
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverDeployServiceURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		status  int
+		root    string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "deploy service link",
+			status: http.StatusOK,
+			root:   `{"links":{"self":{"href":"https://api.sys.example.com"},"credhub":null,"deploy_service":{"href":"https://deploy-service.sys.example.com/"}}}`,
+			want:   "https://deploy-service.sys.example.com",
+		},
+		{
+			name:    "deploy service link missing",
+			status:  http.StatusOK,
+			root:    `{"links":{"self":{"href":"https://api.sys.example.com"},"uaa":{"href":"https://uaa.sys.example.com"}}}`,
+			wantErr: "does not advertise a deploy_service link",
+		},
+		{
+			name:    "deploy service link without href",
+			status:  http.StatusOK,
+			root:    `{"links":{"deploy_service":null}}`,
+			wantErr: "does not advertise a deploy_service link",
+		},
+		{
+			name:    "root not readable",
+			status:  http.StatusBadGateway,
+			root:    `bad gateway`,
+			wantErr: "failed with status 502",
+		},
+		{
+			name:    "invalid root",
+			status:  http.StatusOK,
+			root:    `<html></html>`,
+			wantErr: "failed with invalid character",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/" {
+					t.Errorf("request to %s, want the API root", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.root))
+			}))
+			defer server.Close()
+
+			got, err := DiscoverDeployServiceURL(context.Background(), server.Client(), server.URL)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("DiscoverDeployServiceURL() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DiscoverDeployServiceURL() error = %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("DiscoverDeployServiceURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
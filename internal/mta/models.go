@@ -5,7 +5,7 @@ import "time"
 type FileMetadata struct {
 	Id              string `json:"id,omitempty"`
 	Name            string `json:"name,omitempty"`
-	Size            int32  `json:"size,omitempty"`
+	Size            int64  `json:"size,omitempty"`
 	Digest          string `json:"digest,omitempty"`
 	DigestAlgorithm string `json:"digestAlgorithm,omitempty"`
 	Space           string `json:"space,omitempty"`
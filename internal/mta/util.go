@@ -2,28 +2,85 @@ package mta
 
 import (
 	"archive/zip"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"gopkg.in/yaml.v3"
 )
 
 const (
 	defaultDescriptorPath string = "META-INF/mtad.yaml"
+	manifestPath          string = "META-INF/MANIFEST.MF"
+	rootDescriptorPath    string = "mtad.yaml"
+	mainLogId             string = "MAIN_LOG"
 	FinishedState         string = "FINISHED"
 	AbortedState          string = "ABORTED"
+	ActionRequiredState   string = "ACTION_REQUIRED"
+
+	pollInitialInterval = 2 * time.Second
+	pollMaxInterval     = 15 * time.Second
+
+	uploadPartAttempts = 3
 )
 
+// supportedSchemaVersion matches the major schema versions of descriptors supported by the deploy service.
+var supportedSchemaVersion = regexp.MustCompile(`^[23](\.\d+){0,2}$`)
+
+// ErrActionRequired is returned while polling an operation which waits for a resume or abort action.
+var ErrActionRequired = errors.New("operation is waiting for an action")
+
 type MtaDescriptor struct {
-	SchemaVersion string `yaml:"_schema-version,omitempty"`
-	ID            string `yaml:"ID,omitempty"`
-	Version       string `yaml:"version,omitempty"`
-	Namespace     string `yaml:"namespace,omitempty"`
+	SchemaVersion string                  `yaml:"_schema-version,omitempty"`
+	ID            string                  `yaml:"ID,omitempty"`
+	Version       string                  `yaml:"version,omitempty"`
+	Namespace     string                  `yaml:"namespace,omitempty"`
+	Parameters    map[string]interface{}  `yaml:"parameters,omitempty"`
+	Modules       []MtaDescriptorModule   `yaml:"modules,omitempty"`
+	Resources     []MtaDescriptorResource `yaml:"resources,omitempty"`
+}
+
+type MtaExtensionDescriptor struct {
+	SchemaVersion string                  `yaml:"_schema-version,omitempty"`
+	ID            string                  `yaml:"ID,omitempty"`
+	Extends       string                  `yaml:"extends,omitempty"`
+	Parameters    map[string]interface{}  `yaml:"parameters,omitempty"`
+	Modules       []MtaDescriptorModule   `yaml:"modules,omitempty"`
+	Resources     []MtaDescriptorResource `yaml:"resources,omitempty"`
+}
+
+type MtaDescriptorModule struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type,omitempty"`
+	Path string `yaml:"path,omitempty"`
+}
+
+type MtaDescriptorResource struct {
+	Name       string                 `yaml:"name"`
+	Type       string                 `yaml:"type,omitempty"`
+	Parameters map[string]interface{} `yaml:"parameters,omitempty"`
+}
+
+// An entry of an MTA archive which belongs to modules or resources of the descriptor.
+type mtaArchiveEntry struct {
+	name        string
+	source      string
+	isDirectory bool
+	attribute   string
+	entities    []string
 }
 
 // ref - https://github.com/cloudfoundry/multiapps-cli-plugin/blob/v3.2.2/util/archive_handler.go
@@ -74,6 +131,390 @@ func readZipFile(file *zip.File) ([]byte, error) {
 	return io.ReadAll(reader)
 }
 
+// ModuleNames returns the names of the modules of the descriptor.
+func (d MtaDescriptor) ModuleNames() []string {
+	names := make([]string, 0, len(d.Modules))
+	for _, module := range d.Modules {
+		names = append(names, module.Name)
+	}
+	return names
+}
+
+// ResourceNames returns the names of the resources of the descriptor.
+func (d MtaDescriptor) ResourceNames() []string {
+	names := make([]string, 0, len(d.Resources))
+	for _, res := range d.Resources {
+		names = append(names, res.Name)
+	}
+	return names
+}
+
+// GetMtaDescriptorFromDirectory retrieves the deployment descriptor of an MTA directory.
+func GetMtaDescriptorFromDirectory(directory string) (MtaDescriptor, error) {
+	_, descriptor, err := readMtaDirectoryDescriptor(directory)
+	return descriptor, err
+}
+
+// ParseMtaExtensionDescriptor parses the content of an MTA extension descriptor.
+func ParseMtaExtensionDescriptor(content []byte) (MtaExtensionDescriptor, error) {
+	var extension MtaExtensionDescriptor
+	if err := yaml.Unmarshal(content, &extension); err != nil {
+		return MtaExtensionDescriptor{}, err
+	}
+	if err := extension.validateHeader(); err != nil {
+		return MtaExtensionDescriptor{}, err
+	}
+	return extension, nil
+}
+
+// validateHeader checks the schema version and that the ID of the extension and of the descriptor it extends are set.
+func (e MtaExtensionDescriptor) validateHeader() error {
+	if e.ID == "" || e.Extends == "" {
+		return errors.New("extension descriptor requires an ID and the ID of the descriptor it extends")
+	}
+	if e.SchemaVersion != "" && !supportedSchemaVersion.MatchString(e.SchemaVersion) {
+		return fmt.Errorf("extension %s has the unsupported schema version %s, supported are 2 and 3", e.ID, e.SchemaVersion)
+	}
+	return nil
+}
+
+// Validate checks the header of an extension descriptor and that it extends one of the given IDs and only refers
+// to parameters, modules and resources of the base descriptor.
+func (e MtaExtensionDescriptor) Validate(base MtaDescriptor, extendableIds []string) error {
+	if err := e.validateHeader(); err != nil {
+		return err
+	}
+	var errs []error
+	if !slices.Contains(extendableIds, e.Extends) {
+		errs = append(errs, fmt.Errorf("extension %s extends %s which is neither the MTA %s nor one of its extensions", e.ID, e.Extends, base.ID))
+	}
+	for name := range e.Parameters {
+		if _, ok := base.Parameters[name]; !ok {
+			errs = append(errs, fmt.Errorf("extension %s sets parameter %s which is not defined by MTA %s", e.ID, name, base.ID))
+		}
+	}
+	moduleNames := base.ModuleNames()
+	for _, module := range e.Modules {
+		if !slices.Contains(moduleNames, module.Name) {
+			errs = append(errs, fmt.Errorf("extension %s refers to module %s which is not part of MTA %s", e.ID, module.Name, base.ID))
+		}
+	}
+	resourceNames := base.ResourceNames()
+	for _, res := range e.Resources {
+		if !slices.Contains(resourceNames, res.Name) {
+			errs = append(errs, fmt.Errorf("extension %s refers to resource %s which is not part of MTA %s", e.ID, res.Name, base.ID))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ref - https://github.com/cloudfoundry/multiapps-cli-plugin/blob/v3.2.2/util/mta_archive_builder.go
+// BuildMtaArchive packages an MTA directory with its deployment descriptor into an MTA archive.
+// The archive is built deterministically, so the same content always results in the same bytes.
+func BuildMtaArchive(directory string) ([]byte, MtaDescriptor, error) {
+	descriptorBytes, descriptor, err := readMtaDirectoryDescriptor(directory)
+	if err != nil {
+		return nil, MtaDescriptor{}, err
+	}
+	entries, err := getMtaArchiveEntries(directory, descriptor)
+	if err != nil {
+		return nil, MtaDescriptor{}, err
+	}
+
+	buf := new(bytes.Buffer)
+	writer := zip.NewWriter(buf)
+	if err = writeZipEntry(writer, manifestPath, buildMtaManifest(entries)); err != nil {
+		return nil, MtaDescriptor{}, err
+	}
+	if err = writeZipEntry(writer, defaultDescriptorPath, descriptorBytes); err != nil {
+		return nil, MtaDescriptor{}, err
+	}
+	for _, entry := range entries {
+		if entry.isDirectory {
+			var content []byte
+			if content, err = zipDirectory(entry.source); err == nil {
+				err = writeZipEntry(writer, entry.name, content)
+			}
+		} else {
+			err = writeZipFile(writer, entry.name, entry.source)
+		}
+		if err != nil {
+			return nil, MtaDescriptor{}, err
+		}
+	}
+	if err = writer.Close(); err != nil {
+		return nil, MtaDescriptor{}, err
+	}
+	return buf.Bytes(), descriptor, nil
+}
+
+// HashMtaDirectory computes the SHA256 hash of the deployment descriptor and of the names, modes and content
+// of the files an archive of the MTA directory is built from, without building the archive.
+func HashMtaDirectory(directory string) (string, MtaDescriptor, error) {
+	descriptorBytes, descriptor, err := readMtaDirectoryDescriptor(directory)
+	if err != nil {
+		return "", MtaDescriptor{}, err
+	}
+	entries, err := getMtaArchiveEntries(directory, descriptor)
+	if err != nil {
+		return "", MtaDescriptor{}, err
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%d\x00", defaultDescriptorPath, len(descriptorBytes))
+	hash.Write(descriptorBytes)
+	for _, entry := range entries {
+		if !entry.isDirectory {
+			err = hashFile(hash, entry.name, entry.source)
+		} else {
+			err = filepath.WalkDir(entry.source, func(filePath string, dirEntry fs.DirEntry, err error) error {
+				if err != nil || dirEntry.IsDir() {
+					return err
+				}
+				relativePath, err := filepath.Rel(entry.source, filePath)
+				if err != nil {
+					return err
+				}
+				return hashFile(hash, entry.name+"/"+filepath.ToSlash(relativePath), filePath)
+			})
+		}
+		if err != nil {
+			return "", MtaDescriptor{}, err
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), descriptor, nil
+}
+
+// Streams the name, mode and content of the file at source into the hash.
+func hashFile(hash io.Writer, name string, source string) error {
+	file, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(hash, "%s\x00%s\x00%d\x00", name, info.Mode().Perm(), info.Size())
+	_, err = io.Copy(hash, file)
+	return err
+}
+
+func readMtaDirectoryDescriptor(directory string) ([]byte, MtaDescriptor, error) {
+	descriptorBytes, err := os.ReadFile(filepath.Join(directory, filepath.FromSlash(defaultDescriptorPath)))
+	if errors.Is(err, os.ErrNotExist) {
+		descriptorBytes, err = os.ReadFile(filepath.Join(directory, rootDescriptorPath))
+	}
+	if err != nil {
+		return nil, MtaDescriptor{}, fmt.Errorf("could not read deployment descriptor from directory %s: %w", directory, err)
+	}
+
+	var descriptor MtaDescriptor
+	if err = yaml.Unmarshal(descriptorBytes, &descriptor); err != nil {
+		return nil, MtaDescriptor{}, err
+	}
+	if descriptor.ID == "" {
+		return nil, MtaDescriptor{}, errors.New("could not get a valid mta descriptor from directory")
+	}
+	return descriptorBytes, descriptor, nil
+}
+
+// Collects the module and resource paths of a descriptor, entries used by several modules are only added once.
+func getMtaArchiveEntries(directory string, descriptor MtaDescriptor) ([]*mtaArchiveEntry, error) {
+	var entries []*mtaArchiveEntry
+	entriesBySource := map[string]*mtaArchiveEntry{}
+	add := func(entryPath string, attribute string, entity string) error {
+		cleanPath := filepath.Clean(filepath.FromSlash(entryPath))
+		if filepath.IsAbs(cleanPath) || cleanPath == ".." || strings.HasPrefix(cleanPath, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("path %s of %s is not within the mta directory", entryPath, entity)
+		}
+		source := filepath.Join(directory, cleanPath)
+		if entry, ok := entriesBySource[source+attribute]; ok {
+			entry.entities = append(entry.entities, entity)
+			return nil
+		}
+		info, err := os.Stat(source)
+		if err != nil {
+			return fmt.Errorf("could not read path %s of %s: %w", entryPath, entity, err)
+		}
+		name := filepath.ToSlash(cleanPath)
+		if info.IsDir() {
+			name += ".zip"
+		}
+		entry := &mtaArchiveEntry{name: name, source: source, isDirectory: info.IsDir(), attribute: attribute, entities: []string{entity}}
+		entriesBySource[source+attribute] = entry
+		entries = append(entries, entry)
+		return nil
+	}
+
+	for _, module := range descriptor.Modules {
+		if module.Path == "" {
+			continue
+		}
+		if err := add(module.Path, "MTA-Module", module.Name); err != nil {
+			return nil, err
+		}
+	}
+	for _, res := range descriptor.Resources {
+		resourcePath, ok := res.Parameters["path"].(string)
+		if !ok || resourcePath == "" {
+			continue
+		}
+		if err := add(resourcePath, "MTA-Resource", res.Name); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func buildMtaManifest(entries []*mtaArchiveEntry) []byte {
+	var manifest strings.Builder
+	manifest.WriteString("Manifest-Version: 1.0\r\nCreated-By: terraform-provider-cloudfoundry\r\n\r\n")
+	for _, entry := range entries {
+		contentType := "application/octet-stream"
+		if entry.isDirectory {
+			contentType = "application/zip"
+		}
+		fmt.Fprintf(&manifest, "Name: %s\r\n%s: %s\r\nContent-Type: %s\r\n\r\n", entry.name, entry.attribute, strings.Join(entry.entities, ", "), contentType)
+	}
+	return []byte(manifest.String())
+}
+
+// Zips the content of a directory with fixed timestamps and in lexical order, the file modes are kept.
+func zipDirectory(directory string) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	writer := zip.NewWriter(buf)
+	err := filepath.WalkDir(directory, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		relativePath, err := filepath.Rel(directory, filePath)
+		if err != nil {
+			return err
+		}
+		return writeZipFile(writer, filepath.ToSlash(relativePath), filePath)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Writes a generated entry, such as the manifest, with a fixed timestamp.
+func writeZipEntry(writer *zip.Writer, name string, content []byte) error {
+	header := &zip.FileHeader{Name: name}
+	header.SetMode(0o644)
+	return writeZipHeader(writer, header, content)
+}
+
+// Writes the file at source with its mode but a fixed timestamp, so that only content and modes make up the archive.
+func writeZipFile(writer *zip.Writer, name string, source string) error {
+	// symbolic links are archived as the file they point to
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	return writeZipHeader(writer, header, content)
+}
+
+func writeZipHeader(writer *zip.Writer, header *zip.FileHeader, content []byte) error {
+	header.Method = zip.Deflate
+	header.Modified = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+	entryWriter, err := writer.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = entryWriter.Write(content)
+	return err
+}
+
+// UploadMtaArchive uploads the MTA archive at the given path, see UploadMtaArchiveContent.
+func UploadMtaArchive(ctx context.Context, client *APIClient, spaceGuid string, namespace string, filePath string, partSize int64) (FileMetadata, error) {
+	if filePath == "" {
+		return FileMetadata{}, errors.New("filePath required for uploading")
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return FileMetadata{}, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return FileMetadata{}, err
+	}
+	return UploadMtaArchiveContent(ctx, client, spaceGuid, namespace, filepath.Base(filePath), file, info.Size(), partSize)
+}
+
+// UploadMtaArchiveContent streams an MTA archive to the deploy service. Archives larger than partSize
+// are split into parts of at most partSize bytes, which are uploaded one after the other and retried
+// individually. The returned file carries the comma separated IDs of all parts, which the deploy
+// service accepts as a multi-part archive.
+func UploadMtaArchiveContent(ctx context.Context, client *APIClient, spaceGuid string, namespace string, fileName string, archive io.ReaderAt, size int64, partSize int64) (FileMetadata, error) {
+	if partSize <= 0 || size <= partSize {
+		return uploadMtaArchivePart(ctx, client, spaceGuid, namespace, fileName, io.NewSectionReader(archive, 0, size))
+	}
+
+	parts := int((size + partSize - 1) / partSize)
+	ids := make([]string, 0, parts)
+	var uploaded FileMetadata
+	for i := range parts {
+		offset := int64(i) * partSize
+		part := io.NewSectionReader(archive, offset, min(partSize, size-offset))
+		file, err := uploadMtaArchivePart(ctx, client, spaceGuid, namespace, fmt.Sprintf("%s.part.%d", fileName, i), part)
+		if err != nil {
+			return FileMetadata{}, fmt.Errorf("upload of part %d of %d failed: %w", i+1, parts, err)
+		}
+		tflog.Info(ctx, "uploaded part of MTA archive", map[string]interface{}{
+			"file_name":      fileName,
+			"part":           fmt.Sprintf("%d/%d", i+1, parts),
+			"file_id":        file.Id,
+			"uploaded_bytes": offset + part.Size(),
+			"total_bytes":    size,
+		})
+		ids = append(ids, file.Id)
+		uploaded = file
+	}
+	uploaded.Id = strings.Join(ids, ",")
+	uploaded.Name = fileName
+	uploaded.Size = size
+	return uploaded, nil
+}
+
+// uploadMtaArchivePart uploads a part of an archive and retries it on transport and server errors.
+func uploadMtaArchivePart(ctx context.Context, client *APIClient, spaceGuid string, namespace string, fileName string, part *io.SectionReader) (FileMetadata, error) {
+	interval := pollInitialInterval
+	for attempt := 1; ; attempt++ {
+		file, resp, err := client.DefaultApi.UploadMtaFilePart(ctx, spaceGuid, namespace, fileName, part)
+		if err == nil {
+			return file, nil
+		}
+		if attempt == uploadPartAttempts || (resp != nil && resp.StatusCode < http.StatusInternalServerError) || ctx.Err() != nil {
+			return FileMetadata{}, err
+		}
+		tflog.Warn(ctx, "upload to deploy service failed, retrying", map[string]interface{}{
+			"file_name": fileName,
+			"attempt":   attempt,
+			"error":     err.Error(),
+		})
+		if err := waitForNextPoll(ctx, &interval); err != nil {
+			return FileMetadata{}, err
+		}
+	}
+}
+
 // ref - https://github.com/cloudfoundry/multiapps-cli-plugin/blob/v3.2.2/commands/deploy_command.go
 // CheckOngoingOperation checks for ongoing operation for mta with the specified id and tries to abort it.
 func CheckOngoingOperation(ctx context.Context, client *APIClient, mtaId string, namespace string, spaceGuid string) (bool, error) {
@@ -127,14 +568,29 @@ func isConflicting(operation Operation, mtaID string, namespace string, spaceGui
 
 // Keeps polling the MTA operation by its ID for completion.
 func PollMtaOperation(ctx context.Context, client *APIClient, spaceGuid string, operationId string, targetState string) error {
-
+	var lastMessageId int64 = -1
+	interval := pollInitialInterval
 	for operationState := "RUNNING"; operationState != targetState; {
-		time.Sleep(2 * time.Second)
+		if err := waitForNextPoll(ctx, &interval); err != nil {
+			return err
+		}
 		operationResponse, _, err := client.DefaultApi.GetMtaOperation(ctx, spaceGuid, operationId, "messages")
 		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			return err
 		}
+		for _, message := range operationResponse.Messages {
+			if message.Id > lastMessageId {
+				lastMessageId = message.Id
+				tflog.Info(ctx, message.Text, map[string]interface{}{"operation_id": operationId, "type": message.Type_})
+			}
+		}
 		operationState = operationResponse.State
+		if operationState == ActionRequiredState && targetState != ActionRequiredState {
+			return ErrActionRequired
+		}
 		if operationState == "ERROR" {
 			if messageCount := len(operationResponse.Messages); messageCount > 0 {
 				return fmt.Errorf("last message %s", operationResponse.Messages[messageCount-1].Text)
@@ -145,6 +601,68 @@ func PollMtaOperation(ctx context.Context, client *APIClient, spaceGuid string,
 	return nil
 }
 
+// Waits for the given interval or until the context is done and doubles the interval up to a maximum.
+func waitForNextPoll(ctx context.Context, interval *time.Duration) error {
+	timer := time.NewTimer(*interval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	}
+	*interval = min(*interval*2, pollMaxInterval)
+	return nil
+}
+
+// GetMtaOperationLogTail returns the last lines of the main log of an MTA operation.
+func GetMtaOperationLogTail(ctx context.Context, client *APIClient, spaceGuid string, operationId string, lines int) (string, error) {
+	logs, _, err := client.DefaultApi.GetMtaOperationLogs(ctx, spaceGuid, operationId)
+	if err != nil {
+		return "", err
+	}
+	if len(logs) == 0 {
+		return "", fmt.Errorf("no logs found for operation %s", operationId)
+	}
+	logId := logs[0].Id
+	for _, log := range logs {
+		if log.Id == mainLogId {
+			logId = log.Id
+			break
+		}
+	}
+	content, _, err := client.DefaultApi.GetMtaOperationLogContent(ctx, spaceGuid, operationId, logId)
+	if err != nil {
+		return "", err
+	}
+	logLines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if len(logLines) > lines {
+		logLines = logLines[len(logLines)-lines:]
+	}
+	return strings.Join(logLines, "\n"), nil
+}
+
+// WriteMtaOperationLogs writes all logs of an MTA operation to a mta-op-<operation id> directory below the given one.
+func WriteMtaOperationLogs(ctx context.Context, client *APIClient, spaceGuid string, operationId string, directory string) (string, error) {
+	logs, _, err := client.DefaultApi.GetMtaOperationLogs(ctx, spaceGuid, operationId)
+	if err != nil {
+		return "", err
+	}
+	logDirectory := filepath.Join(directory, "mta-op-"+operationId)
+	if err = os.MkdirAll(logDirectory, 0o755); err != nil {
+		return "", err
+	}
+	for _, log := range logs {
+		content, _, err := client.DefaultApi.GetMtaOperationLogContent(ctx, spaceGuid, operationId, log.Id)
+		if err != nil {
+			return "", err
+		}
+		if err = os.WriteFile(filepath.Join(logDirectory, filepath.Base(log.Id)), []byte(content), 0o644); err != nil {
+			return "", err
+		}
+	}
+	return logDirectory, nil
+}
+
 // ref - https://github.com/cloudfoundry/go-cfclient/blob/main/internal/http/response.go
 // returns the operationID if specified in the Location response header.
 func decodeOperationJobID(resp *http.Response) (string, error) {
@@ -165,20 +683,28 @@ func decodeOperationJobID(resp *http.Response) (string, error) {
 	parts := strings.Split(location.Path, "/")
 	numParts := len(parts)
 	// Ensure 'operations' is the second last element and return the last element as job ID
-	if numParts >= 2 && parts[numParts-2] == "operations" || parts[numParts-2] == "jobs" {
+	if numParts >= 2 && (parts[numParts-2] == "operations" || parts[numParts-2] == "jobs") {
 		operationId = strings.Split(parts[numParts-1], "?")[0]
 	} else {
-		err = fmt.Errorf("did not find operation or job id in location header")
+		err = GenericError{
+			error: fmt.Sprintf("did not find operation or job id in location header %q", location.String()),
+		}
 	}
 	return operationId, err
 }
 
 // Keeps polling the MTA job by its ID for completion.
 func PollMtaJob(ctx context.Context, client *APIClient, spaceGuid string, jobId string, targetState string, xInstance string, namespace string) (jobResponse UploadStatus, err error) {
+	interval := pollInitialInterval
 	for jobState := "RUNNING"; jobState != targetState; {
-		time.Sleep(2 * time.Second)
+		if err = waitForNextPoll(ctx, &interval); err != nil {
+			return jobResponse, err
+		}
 		jobResponse, _, err = client.DefaultApi.GetAsyncUploadJob(ctx, spaceGuid, jobId, xInstance, namespace)
 		if err != nil {
+			if ctx.Err() != nil {
+				return jobResponse, ctx.Err()
+			}
 			return jobResponse, err
 		}
 		jobState = jobResponse.Status
@@ -0,0 +1,410 @@
+package mta
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBuildMtaArchive(t *testing.T) {
+	t.Parallel()
+	directory := t.TempDir()
+	writeFile := func(name string, content string, mode fs.FileMode) {
+		t.Helper()
+		filePath := filepath.Join(directory, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), mode); err != nil {
+			t.Fatal(err)
+		}
+		// the umask must not decide about the mode of the test files
+		if err := os.Chmod(filePath, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("mtad.yaml", "_schema-version: 3.3.0\nID: my-mta\nversion: 1.0.0\nmodules:\n- name: my-app\n  type: nodejs\n  path: app\n- name: my-db\n  type: hdb\n  path: db.zip\n", 0o644)
+	writeFile("app/package.json", `{"name":"my-app"}`, 0o644)
+	writeFile("app/bin/start.sh", "#!/bin/sh\nnode server.js\n", 0o755)
+	writeFile("db.zip", "db content", 0o600)
+
+	first, descriptor, err := BuildMtaArchive(directory)
+	if err != nil {
+		t.Fatalf("BuildMtaArchive() error = %s", err)
+	}
+	if descriptor.ID != "my-mta" {
+		t.Errorf("BuildMtaArchive() descriptor ID = %s, want my-mta", descriptor.ID)
+	}
+
+	// touching the files must not change the archive
+	later := time.Now().Add(time.Hour)
+	err = filepath.WalkDir(directory, func(filePath string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Chtimes(filePath, later, later)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := BuildMtaArchive(directory)
+	if err != nil {
+		t.Fatalf("BuildMtaArchive() error = %s", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("BuildMtaArchive() is not byte-identical for the same content")
+	}
+
+	archive := readTestZip(t, first)
+	for name, mode := range map[string]fs.FileMode{
+		manifestPath:          0o644,
+		defaultDescriptorPath: 0o644,
+		"db.zip":              0o600,
+		"app.zip":             0o644,
+	} {
+		file, ok := archive[name]
+		if !ok {
+			t.Errorf("archive entry %s is missing", name)
+			continue
+		}
+		if file.Mode() != mode {
+			t.Errorf("archive entry %s has mode %s, want %s", name, file.Mode(), mode)
+		}
+		if !file.Modified.Equal(time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("archive entry %s has timestamp %s, want a fixed one", name, file.Modified)
+		}
+	}
+
+	appContent, err := readZipFile(archive["app.zip"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := readTestZip(t, appContent)
+	for name, mode := range map[string]fs.FileMode{
+		"package.json": 0o644,
+		"bin/start.sh": 0o755,
+	} {
+		file, ok := app[name]
+		if !ok {
+			t.Errorf("module archive entry %s is missing", name)
+			continue
+		}
+		if file.Mode() != mode {
+			t.Errorf("module archive entry %s has mode %s, want %s", name, file.Mode(), mode)
+		}
+	}
+}
+
+func TestHashMtaDirectory(t *testing.T) {
+	t.Parallel()
+	directory := t.TempDir()
+	writeFile := func(name string, content string, mode fs.FileMode) {
+		t.Helper()
+		filePath := filepath.Join(directory, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(filePath, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash := func() string {
+		t.Helper()
+		h, descriptor, err := HashMtaDirectory(directory)
+		if err != nil {
+			t.Fatalf("HashMtaDirectory() error = %s", err)
+		}
+		if descriptor.ID != "my-mta" {
+			t.Errorf("HashMtaDirectory() descriptor ID = %s, want my-mta", descriptor.ID)
+		}
+		return h
+	}
+	writeFile("mtad.yaml", "_schema-version: 3.3.0\nID: my-mta\nversion: 1.0.0\nmodules:\n- name: my-app\n  type: nodejs\n  path: app\n", 0o644)
+	writeFile("app/package.json", `{"name":"my-app"}`, 0o644)
+	writeFile("app/bin/start.sh", "#!/bin/sh\nnode server.js\n", 0o755)
+	writeFile("unused.txt", "not part of any module", 0o644)
+
+	first := hash()
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(directory, "app", "package.json"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	writeFile("unused.txt", "still not part of any module", 0o644)
+	if second := hash(); second != first {
+		t.Errorf("HashMtaDirectory() = %s, want %s for the same content", second, first)
+	}
+
+	writeFile("app/bin/start.sh", "#!/bin/sh\nnode server.js\n", 0o644)
+	modeChanged := hash()
+	if modeChanged == first {
+		t.Error("HashMtaDirectory() did not change with the mode of a file")
+	}
+	writeFile("app/package.json", `{"name":"my-app","version":"1.0.1"}`, 0o644)
+	if hash() == modeChanged {
+		t.Error("HashMtaDirectory() did not change with the content of a file")
+	}
+}
+
+func TestMtaExtensionDescriptor_Validate(t *testing.T) {
+	t.Parallel()
+	base := MtaDescriptor{
+		ID:         "my-mta",
+		Parameters: map[string]interface{}{"region": "eu10"},
+		Modules:    []MtaDescriptorModule{{Name: "my-app"}},
+		Resources:  []MtaDescriptorResource{{Name: "my-service"}},
+	}
+	extendableIds := []string{"my-mta", "my-mta-dev"}
+	tests := []struct {
+		name      string
+		extension MtaExtensionDescriptor
+		wantErr   []string
+	}{
+		{
+			name: "valid descriptor",
+			extension: MtaExtensionDescriptor{
+				SchemaVersion: "3.3.0",
+				ID:            "my-mta-prod",
+				Extends:       "my-mta-dev",
+				Parameters:    map[string]interface{}{"region": "us10"},
+				Modules:       []MtaDescriptorModule{{Name: "my-app"}},
+				Resources:     []MtaDescriptorResource{{Name: "my-service"}},
+			},
+		},
+		{
+			name:      "valid descriptor without schema version",
+			extension: MtaExtensionDescriptor{ID: "my-mta-dev", Extends: "my-mta"},
+		},
+		{
+			name:      "missing ID",
+			extension: MtaExtensionDescriptor{SchemaVersion: "3.3", Extends: "my-mta"},
+			wantErr:   []string{"requires an ID"},
+		},
+		{
+			name:      "missing extends",
+			extension: MtaExtensionDescriptor{SchemaVersion: "3.3", ID: "my-mta-dev"},
+			wantErr:   []string{"requires an ID and the ID of the descriptor it extends"},
+		},
+		{
+			name:      "bad schema version",
+			extension: MtaExtensionDescriptor{SchemaVersion: "4.0", ID: "my-mta-dev", Extends: "my-mta"},
+			wantErr:   []string{"unsupported schema version 4.0"},
+		},
+		{
+			name:      "malformed schema version",
+			extension: MtaExtensionDescriptor{SchemaVersion: "3.x", ID: "my-mta-dev", Extends: "my-mta"},
+			wantErr:   []string{"unsupported schema version 3.x"},
+		},
+		{
+			name: "unknown references",
+			extension: MtaExtensionDescriptor{
+				ID:         "my-mta-dev",
+				Extends:    "other-mta",
+				Parameters: map[string]interface{}{"zone": "a"},
+				Modules:    []MtaDescriptorModule{{Name: "other-app"}},
+				Resources:  []MtaDescriptorResource{{Name: "other-service"}},
+			},
+			wantErr: []string{
+				"extends other-mta which is neither the MTA my-mta",
+				"parameter zone",
+				"module other-app",
+				"resource other-service",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.extension.Validate(base, extendableIds)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected an error containing %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMtaExtensionDescriptor(t *testing.T) {
+	t.Parallel()
+	extension, err := ParseMtaExtensionDescriptor([]byte("_schema-version: '3.3'\nID: my-mta-dev\nextends: my-mta\nmodules:\n  - name: my-app\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extension.ID != "my-mta-dev" || extension.Extends != "my-mta" || len(extension.Modules) != 1 {
+		t.Errorf("unexpected extension descriptor %+v", extension)
+	}
+	for content, want := range map[string]string{
+		"ID: my-mta-dev\n":  "requires an ID",
+		"extends: my-mta\n": "requires an ID",
+		"_schema-version: '1'\nID: a\nextends: my-mta\n": "unsupported schema version 1",
+		"ID: [\n": "yaml",
+	} {
+		if _, err := ParseMtaExtensionDescriptor([]byte(content)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("parsing %q: expected an error containing %q, got %v", content, want, err)
+		}
+	}
+}
+
+// fakeUploadService is a deploy service which stores uploaded files and fails the given number of attempts per file.
+type fakeUploadService struct {
+	mu       sync.Mutex
+	failures map[string]int
+	status   int
+	attempts map[string]int
+	files    map[string]string
+	order    []string
+}
+
+func (f *fakeUploadService) start(t *testing.T) *APIClient {
+	t.Helper()
+	f.attempts, f.files = map[string]int{}, map[string]string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/csrf-token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(csrfTokenHeader, "token")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /api/v1/spaces/{space}/files", func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.attempts[header.Filename]++
+		if f.attempts[header.Filename] <= f.failures[header.Filename] {
+			w.WriteHeader(f.status)
+			return
+		}
+		id := fmt.Sprintf("id-%d", len(f.files))
+		f.files[header.Filename] = string(content)
+		f.order = append(f.order, header.Filename)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(FileMetadata{Id: id, Name: header.Filename, Size: int64(len(content)), Space: r.PathValue("space")})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return NewAPIClient(NewConfiguration(server.URL, "test", nil))
+}
+
+func TestUploadMtaArchiveContent(t *testing.T) {
+	t.Parallel()
+	const archive = "0123456789"
+	tests := []struct {
+		name         string
+		partSize     int64
+		failures     map[string]int
+		status       int
+		wantErr      bool
+		wantId       string
+		wantFiles    map[string]string
+		wantAttempts map[string]int
+	}{
+		{
+			name:         "single upload",
+			partSize:     0,
+			wantId:       "id-0",
+			wantFiles:    map[string]string{"my-mta.mtar": archive},
+			wantAttempts: map[string]int{"my-mta.mtar": 1},
+		},
+		{
+			name:      "parts",
+			partSize:  4,
+			wantId:    "id-0,id-1,id-2",
+			wantFiles: map[string]string{"my-mta.mtar.part.0": "0123", "my-mta.mtar.part.1": "4567", "my-mta.mtar.part.2": "89"},
+		},
+		{
+			name:         "part retried after server errors",
+			partSize:     4,
+			failures:     map[string]int{"my-mta.mtar.part.1": 2},
+			status:       http.StatusBadGateway,
+			wantId:       "id-0,id-1,id-2",
+			wantFiles:    map[string]string{"my-mta.mtar.part.0": "0123", "my-mta.mtar.part.1": "4567", "my-mta.mtar.part.2": "89"},
+			wantAttempts: map[string]int{"my-mta.mtar.part.0": 1, "my-mta.mtar.part.1": 3, "my-mta.mtar.part.2": 1},
+		},
+		{
+			name:         "part failing three times",
+			partSize:     4,
+			failures:     map[string]int{"my-mta.mtar.part.1": 3},
+			status:       http.StatusServiceUnavailable,
+			wantErr:      true,
+			wantFiles:    map[string]string{"my-mta.mtar.part.0": "0123"},
+			wantAttempts: map[string]int{"my-mta.mtar.part.0": 1, "my-mta.mtar.part.1": 3},
+		},
+		{
+			name:         "client errors are not retried",
+			partSize:     4,
+			failures:     map[string]int{"my-mta.mtar.part.0": 1},
+			status:       http.StatusRequestEntityTooLarge,
+			wantErr:      true,
+			wantFiles:    map[string]string{},
+			wantAttempts: map[string]int{"my-mta.mtar.part.0": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fake := &fakeUploadService{failures: tt.failures, status: tt.status}
+			client := fake.start(t)
+
+			file, err := UploadMtaArchiveContent(context.Background(), client, "space-guid", "", "my-mta.mtar", strings.NewReader(archive), int64(len(archive)), tt.partSize)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UploadMtaArchiveContent() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && (file.Id != tt.wantId || file.Name != "my-mta.mtar" || file.Size != int64(len(archive))) {
+				t.Errorf("UploadMtaArchiveContent() = %+v, want ID %s for my-mta.mtar with %d bytes", file, tt.wantId, len(archive))
+			}
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+			if !maps.Equal(fake.files, tt.wantFiles) {
+				t.Errorf("uploaded files %v, want %v", fake.files, tt.wantFiles)
+			}
+			if !slices.IsSorted(fake.order) {
+				t.Errorf("parts uploaded in order %v", fake.order)
+			}
+			if tt.wantAttempts != nil && !maps.Equal(fake.attempts, tt.wantAttempts) {
+				t.Errorf("upload attempts %v, want %v", fake.attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func readTestZip(t *testing.T, content []byte) map[string]*zip.File {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("reading zip: %s", err)
+	}
+	files := map[string]*zip.File{}
+	for _, file := range reader.File {
+		files[file.Name] = file
+	}
+	return files
+}
@@ -51,6 +51,10 @@ func (d *appDataSource) Schema(ctx context.Context, req datasource.SchemaRequest
 				MarkdownDescription: "The name of the stack the application will be deployed to.",
 				Computed:            true,
 			},
+			"lifecycle_type": schema.StringAttribute{
+				MarkdownDescription: "The lifecycle used to stage the application, one of 'buildpack', 'docker' or 'cnb'.",
+				Computed:            true,
+			},
 			"buildpacks": schema.SetAttribute{
 				MarkdownDescription: "Multiple buildpacks used to stage the application.",
 				ElementType:         types.StringType,
@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SAP/terraform-provider-cloudfoundry/internal/provider/managers"
+	cfv3client "github.com/cloudfoundry/go-cfclient/v3/client"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ datasource.DataSource = &appLogsDataSource{}
+var _ datasource.DataSourceWithConfigure = &appLogsDataSource{}
+
+const defaultAppLogsLimit = 100
+
+func NewAppLogsDataSource() datasource.DataSource {
+	return &appLogsDataSource{}
+}
+
+type appLogsDataSource struct {
+	cfClient *cfv3client.Client
+}
+
+func (d *appLogsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_app_logs"
+}
+
+func (d *appLogsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Gets the recent logs of a Cloud Foundry application from Log Cache.",
+
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The name of the application to look up",
+				Required:            true,
+			},
+			"space_name": schema.StringAttribute{
+				MarkdownDescription: "The name of the space to look up",
+				Required:            true,
+			},
+			"org_name": schema.StringAttribute{
+				MarkdownDescription: "The name of the associated Cloud Foundry organization to look up",
+				Required:            true,
+			},
+			"source_types": schema.SetAttribute{
+				MarkdownDescription: "The log source types to return e.g. STG, APP, RTR, API or CELL. Defaults to STG and APP.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"limit": schema.Int64Attribute{
+				MarkdownDescription: "The maximum number of log lines to return. Defaults to 100.",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.Between(1, 1000),
+				},
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The GUID of the application.",
+				Computed:            true,
+			},
+			"logs": schema.ListNestedAttribute{
+				MarkdownDescription: "The recent log lines of the application, oldest first.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"timestamp": schema.StringAttribute{
+							MarkdownDescription: "The time the log line was emitted.",
+							Computed:            true,
+						},
+						"source_type": schema.StringAttribute{
+							MarkdownDescription: "The source of the log line e.g. STG or APP/PROC/WEB.",
+							Computed:            true,
+						},
+						"instance_id": schema.StringAttribute{
+							MarkdownDescription: "The index of the instance that emitted the log line.",
+							Computed:            true,
+						},
+						"message_type": schema.StringAttribute{
+							MarkdownDescription: "The stream of the log line, either OUT or ERR.",
+							Computed:            true,
+						},
+						"message": schema.StringAttribute{
+							MarkdownDescription: "The log message.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *appLogsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	session, ok := req.ProviderData.(*managers.Session)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *managers.Session, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.cfClient = session.CFClient
+}
+
+func (d *appLogsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data datasourceAppLogsType
+	diags := req.Config.Get(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	appGUID, err := findAppGUID(ctx, d.cfClient, data.Org.ValueString(), data.Space.ValueString(), data.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Error finding given app", err.Error())
+		return
+	}
+
+	sourceTypes := []string{"STG", "APP"}
+	if !data.SourceTypes.IsNull() {
+		resp.Diagnostics.Append(data.SourceTypes.ElementsAs(ctx, &sourceTypes, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	limit := defaultAppLogsLimit
+	if !data.Limit.IsNull() {
+		limit = int(data.Limit.ValueInt64())
+	}
+
+	entries, err := readRecentAppLogs(ctx, d.cfClient, appGUID, sourceTypes, limit)
+	if err != nil {
+		resp.Diagnostics.AddError("API Error Reading App Logs", "Unable to read logs of app "+data.Name.ValueString()+": "+err.Error())
+		return
+	}
+	data.Id = types.StringValue(appGUID)
+	data.Logs = mapAppLogsValuesToType(entries)
+
+	tflog.Trace(ctx, "read an app logs data source")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
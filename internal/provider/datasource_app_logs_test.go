@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAppLogsDataSource_Configure(t *testing.T) {
+	t.Parallel()
+	t.Run("happy path - read recent logs of an app", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/datasource_app_logs")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + `
+data "cloudfoundry_app_logs" "logs" {
+	name         = "tf-test-do-not-delete-nodejs"
+	space_name   = "tf-space-1"
+	org_name     = "PerformanceTeamBLR"
+	source_types = ["APP/PROC/WEB"]
+	limit        = 5
+}`,
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestMatchResourceAttr("data.cloudfoundry_app_logs.logs", "id", regexpValidUUID),
+						resource.TestCheckResourceAttr("data.cloudfoundry_app_logs.logs", "logs.#", "5"),
+						resource.TestCheckResourceAttr("data.cloudfoundry_app_logs.logs", "logs.0.source_type", "APP/PROC/WEB"),
+						resource.TestCheckResourceAttrSet("data.cloudfoundry_app_logs.logs", "logs.0.timestamp"),
+						resource.TestCheckResourceAttrSet("data.cloudfoundry_app_logs.logs", "logs.0.message"),
+					),
+				},
+			},
+		})
+	})
+	t.Run("error path - invalid limit", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/datasource_app_logs_invalid_limit")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + `
+data "cloudfoundry_app_logs" "logs" {
+	name       = "tf-test-do-not-delete-nodejs"
+	space_name = "tf-space-1"
+	org_name   = "PerformanceTeamBLR"
+	limit      = 0
+}`,
+					ExpectError: regexp.MustCompile(`Invalid Attribute Value`),
+				},
+			},
+		})
+	})
+}
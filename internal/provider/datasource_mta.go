@@ -3,7 +3,6 @@ package provider
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/SAP/terraform-provider-cloudfoundry/internal/mta"
 	"github.com/SAP/terraform-provider-cloudfoundry/internal/provider/managers"
@@ -29,7 +28,8 @@ func NewMtaDataSource() datasource.DataSource {
 
 // Contains reference to the mta client to be used for making the API calls.
 type MtaDataSource struct {
-	mtaClient *mta.APIClient
+	mtaClient    *mta.APIClient
+	mtaClientErr error
 }
 
 func (d *MtaDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -49,16 +49,11 @@ func (d *MtaDataSource) Configure(ctx context.Context, req datasource.ConfigureR
 		return
 	}
 
-	apiEndpointURL := session.CFClient.ApiURL("")
-	conf := mta.NewConfiguration(apiEndpointURL, session.CFClient.UserAgent(), session.CFClient.HTTPAuthClient())
+	// The URL is only required if no deploy_url is set, so it fails on use
+	deployServiceURL, err := session.DeployServiceURL(ctx)
+	conf := mta.NewConfiguration(deployServiceURL, session.CFClient.UserAgent(), session.CFClient.HTTPAuthClient())
 	d.mtaClient = mta.NewAPIClient(conf)
-
-	subDomainWithProtocol := strings.Split(apiEndpointURL, ".")[0]
-	subDomain := strings.Split(subDomainWithProtocol, "//")[1]
-	deploySubdomainWithProtocol := strings.Replace(subDomainWithProtocol, subDomain, "deploy-service", 1)
-	deployURL := strings.Replace(apiEndpointURL, subDomainWithProtocol, deploySubdomainWithProtocol, 1)
-
-	d.mtaClient.ChangeBasePath(deployURL)
+	d.mtaClientErr = err
 }
 
 func (d *MtaDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
@@ -67,7 +62,7 @@ func (d *MtaDataSource) Schema(ctx context.Context, req datasource.SchemaRequest
 
 		Attributes: map[string]schema.Attribute{
 			"deploy_url": schema.StringAttribute{
-				MarkdownDescription: "The URL of the deploy service, if a custom one has been used(should be present in the same landscape). By default 'deploy-service.<system-domain>'",
+				MarkdownDescription: "The URL of the deploy service, if a custom one has been used(should be present in the same landscape). By default the deploy service URL of the provider is used",
 				Optional:            true,
 			},
 			"space": schema.StringAttribute{
@@ -162,8 +157,13 @@ func (d *MtaDataSource) Read(ctx context.Context, req datasource.ReadRequest, re
 		return
 	}
 
-	if !data.DeployUrl.IsNull() {
-		d.mtaClient.ChangeBasePath(data.DeployUrl.ValueString())
+	mtaClient, err := mtaClientForDeployUrl(d.mtaClient, d.mtaClientErr, data.DeployUrl)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to create deploy service client",
+			err.Error(),
+		)
+		return
 	}
 
 	if !data.Namespace.IsNull() {
@@ -171,7 +171,7 @@ func (d *MtaDataSource) Read(ctx context.Context, req datasource.ReadRequest, re
 	}
 
 	//get details of MTA
-	mtas, _, err := d.mtaClient.DefaultApi.GetMtas(ctx, data.Space.ValueString(), namespace, data.Id.ValueString())
+	mtas, _, err := mtaClient.DefaultApi.GetMtas(ctx, data.Space.ValueString(), namespace, data.Id.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to fetch MTA details",
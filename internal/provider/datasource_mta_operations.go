@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SAP/terraform-provider-cloudfoundry/internal/mta"
+	"github.com/SAP/terraform-provider-cloudfoundry/internal/provider/managers"
+	"github.com/SAP/terraform-provider-cloudfoundry/internal/validation"
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ datasource.DataSource = &mtaOperationsDataSource{}
+var _ datasource.DataSourceWithConfigure = &mtaOperationsDataSource{}
+
+func NewMtaOperationsDataSource() datasource.DataSource {
+	return &mtaOperationsDataSource{}
+}
+
+type mtaOperationsDataSource struct {
+	mtaClient    *mta.APIClient
+	mtaClientErr error
+}
+
+func (d *mtaOperationsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_mta_operations"
+}
+
+func (d *mtaOperationsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Gets the operations of the deploy service on Multi Target Applications in a space, e.g. to audit deployments or to check for running operations.",
+
+		Attributes: map[string]schema.Attribute{
+			"deploy_url": schema.StringAttribute{
+				MarkdownDescription: "The URL of the deploy service, if a custom one has been used(should be present in the same landscape). By default the deploy service URL of the provider is used",
+				Optional:            true,
+			},
+			"space": schema.StringAttribute{
+				MarkdownDescription: "The GUID of the space where the operations have been executed",
+				Required:            true,
+				Validators: []validator.String{
+					validation.ValidUUID(),
+				},
+			},
+			"mta_id": schema.StringAttribute{
+				MarkdownDescription: "The MTA ID to filter by",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"namespace": schema.StringAttribute{
+				MarkdownDescription: "The namespace of the MTA to filter by. The deploy service does not support this filter, so the provider filters the returned operations",
+				Optional:            true,
+			},
+			"states": schema.SetAttribute{
+				MarkdownDescription: "The states of the operations to filter by. Any of `RUNNING`, `FINISHED`, `ERROR`, `ABORTED` or `ACTION_REQUIRED`.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(stringvalidator.OneOf(mtaOperationStates...)),
+				},
+			},
+			"last": schema.Int64Attribute{
+				MarkdownDescription: "Only return the given number of most recent operations. Conflicts with `namespace`, as the deploy service does not filter operations by namespace.",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+					int64validator.ConflictsWith(path.MatchRoot("namespace")),
+				},
+			},
+			"operations": schema.ListNestedAttribute{
+				MarkdownDescription: "The list of MTA operations",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "The ID of the operation",
+							Computed:            true,
+						},
+						"process_type": schema.StringAttribute{
+							MarkdownDescription: "The type of the operation, e.g. DEPLOY, BLUE_GREEN_DEPLOY or UNDEPLOY",
+							Computed:            true,
+						},
+						"mta_id": schema.StringAttribute{
+							MarkdownDescription: "The ID of the MTA the operation is executed on",
+							Computed:            true,
+						},
+						"namespace": schema.StringAttribute{
+							MarkdownDescription: "The namespace of the MTA",
+							Computed:            true,
+						},
+						"user": schema.StringAttribute{
+							MarkdownDescription: "The user who started the operation",
+							Computed:            true,
+						},
+						"started_at": schema.StringAttribute{
+							MarkdownDescription: "The time the operation has been started",
+							Computed:            true,
+						},
+						"ended_at": schema.StringAttribute{
+							MarkdownDescription: "The time the operation has ended",
+							Computed:            true,
+						},
+						"state": schema.StringAttribute{
+							MarkdownDescription: "The state of the operation",
+							Computed:            true,
+						},
+						"error_type": schema.StringAttribute{
+							MarkdownDescription: "The type of the error, if the operation failed",
+							Computed:            true,
+						},
+						"acquired_lock": schema.BoolAttribute{
+							MarkdownDescription: "Whether the operation holds the lock on the MTA",
+							Computed:            true,
+						},
+						"parameters": schema.StringAttribute{
+							MarkdownDescription: "The parameters the operation has been started with, as JSON",
+							CustomType:          jsontypes.NormalizedType{},
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *mtaOperationsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	session, ok := req.ProviderData.(*managers.Session)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *managers.Session, got: %T. Please report this issue to the provider developers", req.ProviderData),
+		)
+		return
+	}
+
+	// The URL is only required if no deploy_url is set, so it fails on use
+	deployServiceURL, err := session.DeployServiceURL(ctx)
+	conf := mta.NewConfiguration(deployServiceURL, session.CFClient.UserAgent(), session.CFClient.HTTPAuthClient())
+	d.mtaClient = mta.NewAPIClient(conf)
+	d.mtaClientErr = err
+}
+
+func (d *mtaOperationsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data datasourceMtaOperationsType
+	diags := req.Config.Get(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mtaClient, err := mtaClientForDeployUrl(d.mtaClient, d.mtaClientErr, data.DeployUrl)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to create deploy service client",
+			err.Error(),
+		)
+		return
+	}
+
+	opts := mta.DefaultApiGetMtaOperationsOpts{}
+	if !data.MtaId.IsNull() {
+		opts.MtaId = strtostrptr(data.MtaId.ValueString())
+	}
+	if !data.Last.IsNull() {
+		last := int(data.Last.ValueInt64())
+		opts.Last = &last
+	}
+	if !data.States.IsNull() {
+		resp.Diagnostics.Append(data.States.ElementsAs(ctx, &opts.State, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	operations, _, err := mtaClient.DefaultApi.GetMtaOperations(ctx, data.Space.ValueString(), &opts)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to fetch MTA operations",
+			fmt.Sprintf("Request failed with %s ", err.Error()),
+		)
+		return
+	}
+	data.Operations, diags = mapMtaOperationsValuesToType(operations, data.Namespace)
+	resp.Diagnostics.Append(diags...)
+
+	tflog.Trace(ctx, "read an mta operations data source")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestMtaOperationsDataSource_Configure(t *testing.T) {
+	var (
+		dataSourceName = "data.cloudfoundry_mta_operations.ops"
+		spaceGuid      = "02c0cc92-6ecc-44b1-b7b2-096ca19ee143"
+	)
+	t.Parallel()
+	t.Run("happy path - read mta operations", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/datasource_mta_operations")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + `
+data "cloudfoundry_mta_operations" "ops" {
+	space     = "` + spaceGuid + `"
+	mta_id    = "a.cf.app"
+	namespace = "test"
+	states    = ["FINISHED"]
+}`,
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr(dataSourceName, "operations.0.mta_id", "a.cf.app"),
+						resource.TestCheckResourceAttr(dataSourceName, "operations.0.namespace", "test"),
+						resource.TestCheckResourceAttr(dataSourceName, "operations.0.state", "FINISHED"),
+						resource.TestCheckResourceAttrSet(dataSourceName, "operations.0.process_type"),
+						resource.TestCheckResourceAttrSet(dataSourceName, "operations.0.started_at"),
+					),
+				},
+				{
+					Config: hclProvider(nil) + `
+data "cloudfoundry_mta_operations" "ops" {
+	space = "` + spaceGuid + `"
+	last  = 1
+}`,
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr(dataSourceName, "operations.#", "1"),
+					),
+				},
+			},
+		})
+	})
+	t.Run("error path - last together with namespace", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/datasource_mta_operations_invalid_last")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + `
+data "cloudfoundry_mta_operations" "ops" {
+	space     = "` + spaceGuid + `"
+	namespace = "test"
+	last      = 10
+}`,
+					ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+				},
+			},
+		})
+	})
+	t.Run("error path - invalid state", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/datasource_mta_operations_invalid_state")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + `
+data "cloudfoundry_mta_operations" "ops" {
+	space  = "02c0cc92-6ecc-44b1-b7b2-096ca19ee143"
+	mta_id = "a.cf.app"
+	states = ["DONE"]
+}`,
+					ExpectError: regexp.MustCompile(`Invalid Attribute Value Match`),
+				},
+			},
+		})
+	})
+}
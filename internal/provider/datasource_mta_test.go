@@ -20,17 +20,22 @@ type MtaDataSourceModelPtr struct {
 }
 
 type MtaResourceModelPtr struct {
-	HclType              string
-	HclObjectName        string
-	MtarPath             *string
-	MtarUrl              *string
-	ExtensionDescriptors *string
-	DeployUrl            *string
-	Space                *string
-	Mta                  *string
-	Namespace            *string
-	Id                   *string
-	SourceCodeHash       *string
+	HclType                    string
+	HclObjectName              string
+	MtarPath                   *string
+	MtarUrl                    *string
+	ExtensionDescriptors       *string
+	DeployUrl                  *string
+	Space                      *string
+	Mta                        *string
+	Namespace                  *string
+	Id                         *string
+	SourceCodeHash             *string
+	DeployStrategy             *string
+	BlueGreen                  *string
+	Modules                    *string
+	ExtensionDescriptorContent *string
+	DependsOn                  *string
 }
 
 func hclDataSourceMta(mdsmp *MtaDataSourceModelPtr) string {
@@ -97,6 +102,21 @@ func hclResourceMta(mrmp *MtaResourceModelPtr) string {
 			{{- end -}}
 			{{if .DeployUrl}}
 				deploy_url = "{{.DeployUrl}}"
+			{{- end -}}
+			{{if .DeployStrategy}}
+				deploy_strategy = "{{.DeployStrategy}}"
+			{{- end -}}
+			{{if .BlueGreen}}
+				blue_green = {{.BlueGreen}}
+			{{- end -}}
+			{{if .Modules}}
+				modules = {{.Modules}}
+			{{- end -}}
+			{{if .ExtensionDescriptorContent}}
+				extension_descriptor_content = {{.ExtensionDescriptorContent}}
+			{{- end -}}
+			{{if .DependsOn}}
+				depends_on = {{.DependsOn}}
 			{{- end }}
 			}`
 		tmpl, err := template.New("resource_mtar").Parse(s)
@@ -10,26 +10,33 @@ import (
 )
 
 type ServiceInstanceModelPtr struct {
-	HclType          string
-	HclObjectName    string
-	Name             *string
-	Id               *string
-	Labels           *string
-	Annotations      *string
-	Type             *string
-	Space            *string
-	ServicePlan      *string
-	Parameters       *string
-	Credentials      *string
-	Tags             *string
-	SyslogDrainURL   *string
-	RouteServiceURL  *string
-	MaintenanceInfo  *string
-	UpgradeAvailable *string
-	DashboardURL     *string
-	LastOperation    *string
-	CreatedAt        *string
-	UpdatedAt        *string
+	HclType            string
+	HclObjectName      string
+	Name               *string
+	Id                 *string
+	Labels             *string
+	Annotations        *string
+	Type               *string
+	Space              *string
+	ServicePlan        *string
+	ServiceOffering    *string
+	PlanName           *string
+	ServiceBroker      *string
+	Parameters         *string
+	IgnoreParamKeys    *string
+	Credentials        *string
+	Tags               *string
+	MaintenanceUpgrade *string
+	MaintenanceVersion *string
+	DeleteMode         *string
+	SyslogDrainURL     *string
+	RouteServiceURL    *string
+	MaintenanceInfo    *string
+	UpgradeAvailable   *string
+	DashboardURL       *string
+	LastOperation      *string
+	CreatedAt          *string
+	UpdatedAt          *string
 }
 
 func hclServiceInstance(sip *ServiceInstanceModelPtr) string {
@@ -57,11 +64,23 @@ func hclServiceInstance(sip *ServiceInstanceModelPtr) string {
 			{{if .ServicePlan}}
 				service_plan = "{{.ServicePlan}}"
 			{{- end -}}
+			{{if .ServiceOffering}}
+				service_offering = "{{.ServiceOffering}}"
+			{{- end -}}
+			{{if .PlanName}}
+				plan_name = "{{.PlanName}}"
+			{{- end -}}
+			{{if .ServiceBroker}}
+				service_broker = "{{.ServiceBroker}}"
+			{{- end -}}
 			{{if .Parameters}}
 				parameters = <<EOT
 				{{.Parameters}}
 				EOT
 			{{- end -}}
+			{{if .IgnoreParamKeys}}
+				ignore_parameter_keys = {{.IgnoreParamKeys}}
+			{{- end -}}
 			{{if .Credentials}}
 				credentials = <<EOT
 				{{.Credentials}}
@@ -70,6 +89,15 @@ func hclServiceInstance(sip *ServiceInstanceModelPtr) string {
 			{{if .Tags}}
 				tags = {{.Tags}}
 			{{- end }}
+			{{if .MaintenanceUpgrade}}
+				maintenance_upgrade = "{{.MaintenanceUpgrade}}"
+			{{- end }}
+			{{if .MaintenanceVersion}}
+				maintenance_version = "{{.MaintenanceVersion}}"
+			{{- end -}}
+			{{if .DeleteMode}}
+				delete_mode = "{{.DeleteMode}}"
+			{{- end }}
 		}`
 		tmpl, err := template.New("service_instance").Parse(s)
 		if err != nil {
@@ -1,11 +1,14 @@
 package managers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 
+	"github.com/SAP/terraform-provider-cloudfoundry/internal/mta"
 	"github.com/SAP/terraform-provider-cloudfoundry/internal/version"
 	"github.com/cloudfoundry/go-cfclient/v3/client"
 	config "github.com/cloudfoundry/go-cfclient/v3/config"
@@ -22,10 +25,16 @@ type CloudFoundryProviderConfig struct {
 	Origin            string
 	AccessToken       string
 	RefreshToken      string
+	DeployServiceURL  string
+	DeleteMode        string
 }
 
 type Session struct {
-	CFClient *client.Client
+	CFClient          *client.Client
+	DeleteMode        string
+	deployServiceURL  string
+	deployServiceErr  error
+	deployServiceOnce sync.Once
 }
 
 func (c *CloudFoundryProviderConfig) NewSession(httpClient *http.Client, req provider.ConfigureRequest) (*Session, error) {
@@ -73,7 +82,27 @@ func (c *CloudFoundryProviderConfig) NewSession(httpClient *http.Client, req pro
 		return nil, err
 	}
 	s := Session{
-		CFClient: cf,
+		CFClient:         cf,
+		DeleteMode:       c.DeleteMode,
+		deployServiceURL: c.DeployServiceURL,
 	}
 	return &s, nil
 }
+
+// DeployServiceURL returns the configured URL of the deploy service or discovers it once from the root links
+// of the Cloud Foundry API. It is only resolved by the MTA resources and data sources, so landscapes without
+// a deploy service can be used as well.
+func (s *Session) DeployServiceURL(ctx context.Context) (string, error) {
+	s.deployServiceOnce.Do(func() {
+		if s.deployServiceURL != "" {
+			return
+		}
+		deployServiceURL, err := mta.DiscoverDeployServiceURL(ctx, s.CFClient.HTTPClient(), s.CFClient.ApiURL(""))
+		if err != nil {
+			s.deployServiceErr = fmt.Errorf("unable to determine the deploy service URL, please set deploy_service_url: %w", err)
+			return
+		}
+		s.deployServiceURL = deployServiceURL
+	})
+	return s.deployServiceURL, s.deployServiceErr
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -37,6 +39,8 @@ type CloudFoundryProviderModel struct {
 	Origin            types.String `tfsdk:"origin"`
 	AccessToken       types.String `tfsdk:"access_token"`
 	RefreshToken      types.String `tfsdk:"refresh_token"`
+	DeployServiceURL  types.String `tfsdk:"deploy_service_url"`
+	DefaultDeleteMode types.String `tfsdk:"default_delete_mode"`
 }
 
 func (p *CloudFoundryProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -112,6 +116,20 @@ func (p *CloudFoundryProvider) Schema(ctx context.Context, req provider.SchemaRe
 					stringvalidator.LengthAtLeast(1),
 				},
 			},
+			"deploy_service_url": schema.StringAttribute{
+				MarkdownDescription: "URL of the deploy service used for Multi Target Applications. By default it is read from the `deploy_service` link in the root of the Cloud Foundry API, it has to be set on landscapes which do not advertise this link. It is only required by the MTA resource and data sources.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^https?://`), "must be an http or https URL"),
+				},
+			},
+			"default_delete_mode": schema.StringAttribute{
+				MarkdownDescription: "The delete mode of service instances and service credential bindings which do not set `delete_mode` themselves. One of `normal`, `purge` or `abandon`, defaults to `normal`. Useful to decommission workspaces whose service brokers are no longer available.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(deleteModes...),
+				},
+			},
 		},
 	}
 }
@@ -200,6 +218,8 @@ func getAndSetProviderValues(config *CloudFoundryProviderModel, resp *provider.C
 	cfclientsecret := os.Getenv("CF_CLIENT_SECRET")
 	cfaccesstoken := os.Getenv("CF_ACCESS_TOKEN")
 	cfrefreshtoken := os.Getenv("CF_REFRESH_TOKEN")
+	deployserviceurl := os.Getenv("CF_DEPLOY_SERVICE_URL")
+	defaultdeletemode := os.Getenv("CF_DEFAULT_DELETE_MODE")
 
 	var skipsslvalidation bool
 	var err error
@@ -234,6 +254,20 @@ func getAndSetProviderValues(config *CloudFoundryProviderModel, resp *provider.C
 	if !config.RefreshToken.IsNull() {
 		cfrefreshtoken = config.RefreshToken.ValueString()
 	}
+	if !config.DeployServiceURL.IsNull() {
+		deployserviceurl = config.DeployServiceURL.ValueString()
+	}
+	if !config.DefaultDeleteMode.IsNull() {
+		defaultdeletemode = config.DefaultDeleteMode.ValueString()
+	}
+	if defaultdeletemode != "" && !slices.Contains(deleteModes, defaultdeletemode) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("default_delete_mode"),
+			"Invalid field default_delete_mode",
+			fmt.Sprintf("The provider cannot use the default delete mode %q. Ensure default_delete_mode or CF_DEFAULT_DELETE_MODE is one of %s", defaultdeletemode, strings.Join(deleteModes, ", ")),
+		)
+		return nil
+	}
 	checkConfig(resp, endpoint, user, password, cfclientid, cfclientsecret, cfaccesstoken)
 	if resp.Diagnostics.HasError() {
 		return nil
@@ -252,6 +286,8 @@ func getAndSetProviderValues(config *CloudFoundryProviderModel, resp *provider.C
 		Origin:            origin,
 		AccessToken:       cfaccesstoken,
 		RefreshToken:      cfrefreshtoken,
+		DeployServiceURL:  strings.TrimSuffix(deployserviceurl, "/"),
+		DeleteMode:        defaultdeletemode,
 	}
 	return &c
 }
@@ -307,6 +343,7 @@ func (p *CloudFoundryProvider) Resources(ctx context.Context) []func() resource.
 		NewServiceRouteBindingResource,
 		NewBuildpackResource,
 		NewServiceBrokerResource,
+		NewServiceKeyRotationResource,
 		NewUserGroupsResource,
 	}
 }
@@ -328,10 +365,12 @@ func (p *CloudFoundryProvider) DataSources(ctx context.Context) []func() datasou
 		NewAppDataSource,
 		NewServiceCredentialBindingDataSource,
 		NewMtaDataSource,
+		NewMtaOperationsDataSource,
 		NewIsolationSegmentDataSource,
 		NewIsolationSegmentEntitlementDataSource,
 		NewStackDataSource,
 		NewRemoteMtarHashDataSource,
+		NewAppLogsDataSource,
 	}
 }
 
@@ -273,6 +273,7 @@ func TestCloudFoundryProvider_HasResources(t *testing.T) {
 		"cloudfoundry_service_route_binding",
 		"cloudfoundry_buildpack",
 		"cloudfoundry_service_broker",
+		"cloudfoundry_service_key_rotation",
 		"cloudfoundry_user_groups",
 	}
 
@@ -307,10 +308,12 @@ func TestProvider_HasDataSources(t *testing.T) {
 		"cloudfoundry_app",
 		"cloudfoundry_service_credential_binding",
 		"cloudfoundry_mta",
+		"cloudfoundry_mta_operations",
 		"cloudfoundry_isolation_segment",
 		"cloudfoundry_isolation_segment_entitlement",
 		"cloudfoundry_stack",
 		"cloudfoundry_remote_mtar_hash",
+		"cloudfoundry_app_logs",
 	}
 
 	ctx := context.Background()
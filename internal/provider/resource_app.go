@@ -2,15 +2,25 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/SAP/terraform-provider-cloudfoundry/internal/provider/managers"
+	"github.com/SAP/terraform-provider-cloudfoundry/internal/validation"
 	cfv3client "github.com/cloudfoundry/go-cfclient/v3/client"
 	cfv3operation "github.com/cloudfoundry/go-cfclient/v3/operation"
 	cfv3resource "github.com/cloudfoundry/go-cfclient/v3/resource"
 	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
 	"github.com/hashicorp/terraform-plugin-framework-validators/boolvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
@@ -25,13 +35,22 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"gopkg.in/yaml.v2"
 )
 
 var (
-	_ resource.Resource                = &appResource{}
-	_ resource.ResourceWithConfigure   = &appResource{}
-	_ resource.ResourceWithImportState = &appResource{}
+	_ resource.Resource                   = &appResource{}
+	_ resource.ResourceWithConfigure      = &appResource{}
+	_ resource.ResourceWithImportState    = &appResource{}
+	_ resource.ResourceWithValidateConfig = &appResource{}
+	_ resource.ResourceWithModifyPlan     = &appResource{}
+)
+
+const (
+	defaultWaitForTimeout = 5 * time.Minute
+	appPollInterval       = 5 * time.Second
+	appFailureLogLines    = 50
 )
 
 func NewAppResource() resource.Resource {
@@ -51,26 +70,27 @@ func (r *appResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 		MarkdownDescription: "Provides a Cloud Foundry resource to manage applications.",
 		Attributes: map[string]schema.Attribute{
 			"name": schema.StringAttribute{
-				MarkdownDescription: "The name of the application.",
+				MarkdownDescription: "The name of the application. A changed name renames the application in place.",
 				Required:            true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
 			},
 			"space_name": schema.StringAttribute{
-				MarkdownDescription: "The name of the associated Cloud Foundry space.",
+				MarkdownDescription: "The name of the associated Cloud Foundry space. A change recreates the application unless zero_downtime_space_move is set.",
 				Required:            true,
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					requiresReplaceUnlessSpaceMove(),
 				},
 			},
 			"org_name": schema.StringAttribute{
-				MarkdownDescription: "The name of the associated Cloud Foundry organization.",
+				MarkdownDescription: "The name of the associated Cloud Foundry organization. A change recreates the application unless zero_downtime_space_move is set.",
 				Required:            true,
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					requiresReplaceUnlessSpaceMove(),
 				},
 			},
+			"zero_downtime_space_move": schema.BoolAttribute{
+				MarkdownDescription: "Moves the application to another space or org without downtime instead of recreating it. A new application is pushed to the target space, the routes of the old application are shared with the target space and mapped to the new application, then the old application is deleted and the ownership of the routes is transferred. Requires route sharing to be enabled on the platform and the bound service instances to be available in the target space. The application gets a new GUID and loses its history.",
+				Optional:            true,
+			},
 			"stack": schema.StringAttribute{
 				MarkdownDescription: "The base operating system and file system that your application will execute in. Please refer to the [docs](https://v3-apidocs.cloudfoundry.org/version/3.155.0/index.html#stacks) for more information",
 				Optional:            true,
@@ -85,11 +105,55 @@ func (r *appResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 				},
 				Optional: true,
 			},
+			"lifecycle_type": schema.StringAttribute{
+				MarkdownDescription: "The lifecycle used to stage the application. Valid values are 'buildpack', 'docker' and 'cnb'. Defaults to 'docker' if a docker image is given, else to 'buildpack'. With 'cnb' the buildpacks are Cloud Native Buildpacks which can also be referenced as image URIs e.g. docker://docker.io/paketobuildpacks/nodejs.",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(buildpackLifecycle, dockerLifecycle, cnbLifecycle),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIfConfigured(),
+				},
+			},
+			"cnb_credentials": schema.StringAttribute{
+				CustomType:          jsontypes.NormalizedType{},
+				MarkdownDescription: "Credentials for private registries hosting Cloud Native Buildpacks as a json object keyed by registry e.g. {\"registry.example.com\": {\"username\": \"user\", \"password\": \"secret\"}}. Only valid with the 'cnb' lifecycle.",
+				Optional:            true,
+				Sensitive:           true,
+			},
 			"path": schema.StringAttribute{
-				MarkdownDescription: "The path to the zip file for the application.",
+				MarkdownDescription: "The path to the zip file for the application. Exactly one of path and docker_image has to be set unless the application is described by a manifest.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("docker_image")),
+				},
+			},
+			"manifest_path": schema.StringAttribute{
+				MarkdownDescription: "The path to a cf manifest.yml describing the application. The application with the name of the resource is used, or the only application of the manifest. A relative path of the application in the manifest is resolved against the directory of the manifest. Attributes set on the resource take precedence over the manifest, labels and annotations are only taken from the resource.",
 				Optional:            true,
 				Validators: []validator.String{
-					stringvalidator.ExactlyOneOf(path.MatchRoot("docker_image"), path.MatchRoot("path")),
+					stringvalidator.ConflictsWith(path.MatchRoot("manifest")),
+				},
+			},
+			"manifest": schema.StringAttribute{
+				MarkdownDescription: "The content of a cf manifest describing the application, as an alternative to manifest_path.",
+				Optional:            true,
+			},
+			"vars": schema.MapAttribute{
+				MarkdownDescription: "Values for the ((variables)) of the manifest. Takes precedence over vars_files.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.Map{
+					mapvalidator.SizeAtLeast(1),
+				},
+			},
+			"vars_files": schema.ListAttribute{
+				MarkdownDescription: "Paths to yaml files with values for the ((variables)) of the manifest. Later files take precedence.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
 				},
 			},
 			"source_code_hash": schema.StringAttribute{
@@ -132,6 +196,69 @@ func (r *appResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 					stringvalidator.OneOf("none", "rolling", "blue-green"),
 				},
 			},
+			"state": schema.StringAttribute{
+				MarkdownDescription: "The desired state of the application. Valid values are 'STARTED' and 'STOPPED'. If not set, the application is started on push and its state is not managed.",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("STARTED", "STOPPED"),
+				},
+			},
+			"restart_triggers": schema.MapAttribute{
+				MarkdownDescription: "Arbitrary key/value pairs which restart the application in place when changed, e.g. the hash of a rotated credential. The restart respects the deployment strategy of the application.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"wait_for": schema.SingleNestedAttribute{
+				MarkdownDescription: "Waits for the application to become healthy after it has been pushed. The apply fails right away with the crash reasons once instances crash, and with the recent events of the app if the conditions are not met in time.",
+				Optional:            true,
+				Attributes: map[string]schema.Attribute{
+					"min_running_instances": schema.MapAttribute{
+						MarkdownDescription: "The minimum number of RUNNING instances per process type e.g. { web = 2 }. Defaults to the desired number of instances of every process.",
+						ElementType:         types.Int64Type,
+						Optional:            true,
+						Validators: []validator.Map{
+							mapvalidator.SizeAtLeast(1),
+							mapvalidator.ValueInt64sAre(int64validator.AtLeast(0)),
+						},
+					},
+					"timeout": schema.Int64Attribute{
+						MarkdownDescription: "The time in seconds to wait for the application to become healthy. Defaults to 300.",
+						Optional:            true,
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+					"smoke_check": schema.SingleNestedAttribute{
+						MarkdownDescription: "An HTTP request against a route of the application which has to succeed once the instances are running.",
+						Optional:            true,
+						Attributes: map[string]schema.Attribute{
+							"route": schema.StringAttribute{
+								MarkdownDescription: "The route to check. Defaults to the first route mapped to the application.",
+								Optional:            true,
+							},
+							"path": schema.StringAttribute{
+								MarkdownDescription: "The path to request on the route. Defaults to '/'.",
+								Optional:            true,
+							},
+							"expected_status": schema.Int64Attribute{
+								MarkdownDescription: "The expected HTTP status code of the response. Defaults to 200.",
+								Optional:            true,
+								Validators: []validator.Int64{
+									int64validator.Between(100, 599),
+								},
+							},
+							"expected_body": schema.StringAttribute{
+								MarkdownDescription: "A regular expression the response body has to match.",
+								Optional:            true,
+								Validators: []validator.String{
+									validation.ValidRegex(),
+								},
+							},
+						},
+					},
+				},
+			},
 			"service_bindings": schema.SetNestedAttribute{
 				MarkdownDescription: "Service instances to bind to the application.",
 				Optional:            true,
@@ -285,6 +412,19 @@ func (r *appResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 	}
 }
 
+// requiresReplaceUnlessSpaceMove recreates the app on a space or org change unless the zero downtime move is enabled.
+func requiresReplaceUnlessSpaceMove() planmodifier.String {
+	return stringplanmodifier.RequiresReplaceIf(
+		func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+			var move types.Bool
+			resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("zero_downtime_space_move"), &move)...)
+			resp.RequiresReplace = !move.ValueBool()
+		},
+		"Recreates the application unless zero_downtime_space_move is set.",
+		"Recreates the application unless zero_downtime_space_move is set.",
+	)
+}
+
 func (r *appResource) ProcessSchemaAttributes() map[string]schema.Attribute {
 	pSchema := map[string]schema.Attribute{
 		"type": schema.StringAttribute{
@@ -389,6 +529,99 @@ func (r *appResource) Configure(ctx context.Context, req resource.ConfigureReque
 	r.cfClient = session.CFClient
 }
 
+func (r *appResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config AppType
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if config.Manifest.IsNull() && config.ManifestPath.IsNull() {
+		if !config.Vars.IsNull() || !config.VarsFiles.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("vars"),
+				"Invalid attribute combination",
+				"vars and vars_files can only be set along with manifest or manifest_path.",
+			)
+		}
+		if config.Path.IsNull() && config.DockerImage.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("path"),
+				"Invalid Attribute Combination",
+				"Exactly one of path and docker_image must be configured unless the application is described by manifest or manifest_path.",
+			)
+		}
+	} else if !config.Manifest.IsUnknown() && !config.ManifestPath.IsUnknown() && !config.Vars.IsUnknown() && !config.VarsFiles.IsUnknown() && !config.Name.IsUnknown() {
+		if _, err := config.loadAppManifest(ctx); err != nil {
+			resp.Diagnostics.AddError("Invalid application manifest", err.Error())
+		}
+	}
+	if config.Lifecycle.IsUnknown() {
+		return
+	}
+	lifecycle := config.Lifecycle.ValueString()
+	switch {
+	case lifecycle == dockerLifecycle && config.DockerImage.IsNull() && !config.usesManifest():
+		resp.Diagnostics.AddAttributeError(
+			path.Root("lifecycle_type"),
+			"Missing docker image",
+			"The docker lifecycle requires the attribute docker_image to be set.",
+		)
+	case (lifecycle == buildpackLifecycle || lifecycle == cnbLifecycle) && !config.DockerImage.IsNull():
+		resp.Diagnostics.AddAttributeError(
+			path.Root("lifecycle_type"),
+			"Invalid lifecycle",
+			fmt.Sprintf("The %s lifecycle cannot be used with a docker image, please provide the application bits via path.", lifecycle),
+		)
+	}
+	if lifecycle != cnbLifecycle && !config.CNBCredentials.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("cnb_credentials"),
+			"Invalid attribute combination",
+			"cnb_credentials can only be set along with lifecycle 'cnb'.",
+		)
+	}
+	if lifecycle == cnbLifecycle && config.Strategy.ValueString() == "blue-green" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("strategy"),
+			"Invalid attribute combination",
+			"The blue-green strategy is not supported with the cnb lifecycle, please use 'rolling' instead.",
+		)
+	}
+}
+
+func (r *appResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	var manifest, manifestPath types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("manifest"), &manifest)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("manifest_path"), &manifestPath)...)
+	if resp.Diagnostics.HasError() || (manifest.IsNull() && manifestPath.IsNull()) {
+		return
+	}
+	var config, plan AppType
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if config.Manifest.IsUnknown() || config.ManifestPath.IsUnknown() || config.Vars.IsUnknown() || config.VarsFiles.IsUnknown() {
+		return
+	}
+	fileManifest, err := config.loadAppManifest(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid application manifest", err.Error())
+		return
+	}
+	manifestType, diags := mapAppValuesToType(ctx, normalizeAppManifest(fileManifest), &cfv3resource.App{Metadata: cfv3resource.NewMetadata()}, &config)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(plan.applyManifestValues(ctx, &manifestType, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
 func (r *appResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	r.upsert(ctx, &req.Plan, nil, &resp.State, &resp.Diagnostics)
 }
@@ -419,6 +652,19 @@ func (r *appResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 	plan, diags := mapAppValuesToType(ctx, appManifest.Applications[0], appResp, &appType)
 	resp.Diagnostics.Append(diags...)
 	plan.CopyConfigAttributes(&appType)
+	if appType.usesManifest() {
+		// attributes set through the manifest are only kept in the state when they drifted from the manifest
+		var manifestType *AppType
+		fileManifest, err := appType.loadAppManifest(ctx)
+		if err != nil {
+			resp.Diagnostics.AddWarning("Unable to read app manifest", "Drift of attributes set through the manifest cannot be detected: "+err.Error())
+		} else {
+			mapped, diags := mapAppValuesToType(ctx, normalizeAppManifest(fileManifest), appResp, &appType)
+			resp.Diagnostics.Append(diags...)
+			manifestType = &mapped
+		}
+		plan.maskManifestAttributes(&appType, manifestType)
+	}
 	resp.State.Set(ctx, &plan)
 }
 
@@ -449,10 +695,59 @@ func (r *appResource) upsert(ctx context.Context, reqPlan *tfsdk.Plan, reqState
 			return
 		}
 	}
-	appResp, err := r.push(desiredState, appManifestValue, ctx)
-	if err != nil {
-		respDiags.AddError("Error pushing app", err.Error())
-		return
+	if desiredState.usesManifest() {
+		fileManifest, err := desiredState.loadAppManifest(ctx)
+		if err != nil {
+			respDiags.AddError("Error reading app manifest", err.Error())
+			return
+		}
+		// labels and annotations are only managed through the resource attributes
+		fileManifest.Metadata = nil
+		appManifestValue, err = mergeAppManifests(fileManifest, appManifestValue)
+		if err != nil {
+			respDiags.AddError("Error merging app manifest", err.Error())
+			return
+		}
+	}
+	var appResp *cfv3resource.App
+	var err error
+	moved := reqState != nil && (!desiredState.Space.Equal(previousState.Space) || !desiredState.Org.Equal(previousState.Org))
+	if reqState != nil && !moved && !desiredState.Name.Equal(previousState.Name) {
+		_, err = r.cfClient.Applications.Update(ctx, previousState.ID.ValueString(), &cfv3resource.AppUpdate{
+			Name: desiredState.Name.ValueString(),
+		})
+		if err != nil {
+			respDiags.AddError("Error renaming app", "Unable to rename app "+previousState.Name.ValueString()+": "+err.Error())
+			return
+		}
+	}
+	switch {
+	case moved:
+		appResp, err = r.moveApp(ctx, desiredState, previousState, appManifestValue)
+		if err != nil {
+			respDiags.AddError("Error moving app", err.Error())
+			return
+		}
+	case reqState != nil && !desiredState.requiresPush(&previousState):
+		appResp, err = r.applyState(ctx, desiredState, previousState)
+		if err != nil {
+			respDiags.AddError("Error changing app state", err.Error()+r.appFailureLogs(ctx, previousState))
+			return
+		}
+	default:
+		appResp, err = r.push(desiredState, appManifestValue, ctx)
+		if err != nil {
+			respDiags.AddError("Error pushing app", err.Error()+r.appFailureLogs(ctx, desiredState))
+			return
+		}
+	}
+	// a push always starts the app
+	if desiredState.State.ValueString() == "STOPPED" && appResp.State != "STOPPED" {
+		appResp, err = r.cfClient.Applications.Stop(ctx, appResp.GUID)
+		if err != nil {
+			respDiags.AddError("Error stopping app", err.Error())
+			return
+		}
 	}
 	manifestRespRaw, err := r.cfClient.Manifests.Generate(ctx, appResp.GUID)
 	if err != nil {
@@ -466,16 +761,36 @@ func (r *appResource) upsert(ctx context.Context, reqPlan *tfsdk.Plan, reqState
 	plan, diags := mapAppValuesToType(ctx, manifest.Applications[0], appResp, &desiredState)
 	respDiags.Append(diags...)
 	plan.CopyConfigAttributes(&desiredState)
+	if desiredState.usesManifest() {
+		plan.maskManifestAttributes(&desiredState, nil)
+	}
 	respDiags.Append(respState.Set(ctx, &plan)...)
+	if desiredState.WaitFor != nil && appResp.State == "STARTED" {
+		// the state is kept so that a failing app gets tainted instead of being orphaned
+		if err = r.waitForApp(ctx, appResp, desiredState.WaitFor, manifest.Applications[0].Routes); err != nil {
+			respDiags.AddError("Application did not become healthy", err.Error()+r.appFailureLogs(ctx, plan))
+		}
+	}
 }
 func (r *appResource) push(appType AppType, appManifestValue *cfv3operation.AppManifest, ctx context.Context) (*cfv3resource.App, error) {
 	var file *os.File
 	var err error
-	if !appType.Path.IsNull() {
-		file, err = os.Open(appType.Path.ValueString())
+	bitsPath := appType.Path.ValueString()
+	if appType.Path.IsNull() {
+		bitsPath = appManifestValue.Path
+	}
+	// the bits are uploaded by the provider, the path of a manifest is local to the machine running terraform
+	appManifestValue.Path = ""
+	if bitsPath != "" {
+		file, err = os.Open(bitsPath)
 		if err != nil {
 			return nil, err
 		}
+	} else if appManifestValue.Docker == nil {
+		return nil, fmt.Errorf("neither a path nor a docker image is given for app %s", appManifestValue.Name)
+	}
+	if appType.Lifecycle.ValueString() == cnbLifecycle {
+		return r.pushCNB(ctx, appType, appManifestValue, file)
 	}
 	manifestOp := cfv3operation.NewAppPushOperation(r.cfClient, appType.Org.ValueString(), appType.Space.ValueString())
 	if !appType.Strategy.IsNull() {
@@ -497,6 +812,512 @@ func (r *appResource) push(appType AppType, appManifestValue *cfv3operation.AppM
 	return appResp, nil
 }
 
+// pushCNB stages and starts an application with the cnb lifecycle which is not covered by the cf-client push operation.
+func (r *appResource) pushCNB(ctx context.Context, appType AppType, appManifestValue *cfv3operation.AppManifest, file io.Reader) (*cfv3resource.App, error) {
+	space, err := findSpace(ctx, r.cfClient, appType.Org.ValueString(), appType.Space.ValueString())
+	if err != nil {
+		return nil, err
+	}
+
+	manifestBytes, err := yaml.Marshal(&cnbManifest{
+		Applications: []*cnbAppManifest{{AppManifest: *appManifestValue, Lifecycle: cnbLifecycle}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling application manifest: %w", err)
+	}
+	jobID, err := r.cfClient.Manifests.ApplyManifest(ctx, space.GUID, string(manifestBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error applying application manifest to space %s: %w", space.Name, err)
+	}
+	if err = pollJob(ctx, *r.cfClient, jobID, defaultTimeout); err != nil {
+		return nil, fmt.Errorf("error waiting for application manifest to finish applying to space %s: %w", space.Name, err)
+	}
+
+	appOpts := cfv3client.NewAppListOptions()
+	appOpts.Names.EqualTo(appManifestValue.Name)
+	appOpts.SpaceGUIDs.EqualTo(space.GUID)
+	app, err := r.cfClient.Applications.Single(ctx, appOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	// registry credentials are part of the app lifecycle data and are picked up by every subsequent build
+	if !appType.CNBCredentials.IsNull() {
+		var credentials map[string]cnbRegistryCredentials
+		if err = json.Unmarshal([]byte(appType.CNBCredentials.ValueString()), &credentials); err != nil {
+			return nil, fmt.Errorf("error unmarshalling cnb_credentials: %w", err)
+		}
+		lifecycle := cnbLifecycleUpdate{}
+		lifecycle.Lifecycle.Type = cnbLifecycle
+		lifecycle.Lifecycle.Data.Buildpacks = app.Lifecycle.BuildpackData.Buildpacks
+		lifecycle.Lifecycle.Data.Stack = app.Lifecycle.BuildpackData.Stack
+		lifecycle.Lifecycle.Data.Credentials = credentials
+		if err = cfRawRequest(ctx, r.cfClient, http.MethodPatch, "/v3/apps/"+app.GUID, &lifecycle, nil); err != nil {
+			return nil, fmt.Errorf("error setting cnb registry credentials for app %s: %w", app.Name, err)
+		}
+	}
+
+	pkg, err := r.cfClient.Packages.Create(ctx, cfv3resource.NewPackageCreate(app.GUID))
+	if err != nil {
+		return nil, fmt.Errorf("error creating package bits for app %s: %w", app.Name, err)
+	}
+	if _, err = r.cfClient.Packages.Upload(ctx, pkg.GUID, file); err != nil {
+		return nil, fmt.Errorf("error uploading package bits for app %s: %w", app.Name, err)
+	}
+	if err = r.cfClient.Packages.PollReady(ctx, pkg.GUID, nil); err != nil {
+		return nil, fmt.Errorf("error while waiting for package to process for app %s: %w", app.Name, err)
+	}
+
+	// without an explicit lifecycle the build uses the cnb lifecycle of the app
+	build, err := r.cfClient.Builds.Create(ctx, cfv3resource.NewBuildCreate(pkg.GUID))
+	if err != nil {
+		return nil, fmt.Errorf("error creating build from package for app %s: %w", app.Name, err)
+	}
+	if err = r.cfClient.Builds.PollStaged(ctx, build.GUID, nil); err != nil {
+		return nil, fmt.Errorf("error while waiting for app %s package to build: %w", app.Name, err)
+	}
+	dropletOpts := cfv3client.NewDropletPackageListOptions()
+	dropletOpts.States.EqualTo(cfv3resource.DropletStateStaged.String())
+	droplet, err := r.cfClient.Droplets.SingleForPackage(ctx, pkg.GUID, dropletOpts)
+	if err != nil {
+		return nil, fmt.Errorf("error finding droplet for app %s: %w", app.Name, err)
+	}
+
+	if app.State == "STARTED" && appType.Strategy.ValueString() == "rolling" {
+		return r.deploy(ctx, app, droplet.GUID)
+	}
+
+	if _, err = r.cfClient.Droplets.SetCurrentAssociationForApp(ctx, app.GUID, droplet.GUID); err != nil {
+		return nil, err
+	}
+	if app.State == "STARTED" {
+		return r.cfClient.Applications.Restart(ctx, app.GUID)
+	}
+	return r.cfClient.Applications.Start(ctx, app.GUID)
+}
+
+// moveApp moves the app to another space without downtime by pushing a new app to the target space,
+// moving the routes over and deleting the old app afterwards. Until the old app is deleted, a failure
+// unmaps the routes from the new app and revokes the route shares made for the move.
+func (r *appResource) moveApp(ctx context.Context, desired AppType, previous AppType, appManifestValue *cfv3operation.AppManifest) (_ *cfv3resource.App, err error) {
+	oldSpace, err := findSpace(ctx, r.cfClient, previous.Org.ValueString(), previous.Space.ValueString())
+	if err != nil {
+		return nil, err
+	}
+	space, err := findSpace(ctx, r.cfClient, desired.Org.ValueString(), desired.Space.ValueString())
+	if err != nil {
+		return nil, err
+	}
+	routes, err := r.cfClient.Routes.ListForAppAll(ctx, previous.ID.ValueString(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list routes of app %s: %w", previous.Name.ValueString(), err)
+	}
+
+	var (
+		sharedRoutes  []*cfv3resource.Route
+		destinations  = map[string]string{}
+		oldAppDeleted bool
+	)
+	defer func() {
+		if err == nil || oldAppDeleted {
+			return
+		}
+		for routeGUID, destinationGUID := range destinations {
+			if rollbackErr := r.cfClient.Routes.RemoveDestination(ctx, routeGUID, destinationGUID); rollbackErr != nil {
+				err = fmt.Errorf("%w\nunable to unmap route %s from the new app: %s", err, routeGUID, rollbackErr.Error())
+			}
+		}
+		for _, route := range sharedRoutes {
+			if rollbackErr := r.cfClient.Routes.UnShareWithSpace(ctx, route.GUID, space.GUID); rollbackErr != nil {
+				err = fmt.Errorf("%w\nunable to unshare route %s with space %s: %s", err, route.URL, space.Name, rollbackErr.Error())
+			}
+		}
+	}()
+
+	// routes owned by or already shared with the target space can be mapped as they are
+	for _, route := range routes {
+		if routeOwner(route) == space.GUID {
+			continue
+		}
+		sharedSpaces, err := r.cfClient.Routes.GetSharedSpacesRelationships(ctx, route.GUID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the shared spaces of route %s: %w", route.URL, err)
+		}
+		if slices.ContainsFunc(sharedSpaces.Data, func(s cfv3resource.Relationship) bool { return s.GUID == space.GUID }) {
+			continue
+		}
+		if _, err = r.cfClient.Routes.ShareWithSpace(ctx, route.GUID, space.GUID); err != nil {
+			return nil, fmt.Errorf("unable to share route %s with space %s: %w", route.URL, space.Name, err)
+		}
+		sharedRoutes = append(sharedRoutes, route)
+	}
+
+	// the routes are mapped once the new app is running
+	newManifest := *appManifestValue
+	newManifest.Routes = nil
+	newManifest.RandomRoute = false
+	newManifest.NoRoute = true
+	app, err := r.push(desired, &newManifest, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error pushing app to space %s, the old app is left untouched: %w%s", space.Name, err, r.appFailureLogs(ctx, desired))
+	}
+	if app.State == "STARTED" {
+		waitFor := &AppWaitFor{MinRunningInstances: types.MapNull(types.Int64Type), Timeout: types.Int64Null()}
+		if desired.WaitFor != nil {
+			waitFor.MinRunningInstances = desired.WaitFor.MinRunningInstances
+			waitFor.Timeout = desired.WaitFor.Timeout
+		}
+		if err = r.waitForApp(ctx, app, waitFor, nil); err != nil {
+			return nil, fmt.Errorf("new app in space %s did not become healthy, the old app is left untouched: %w", space.Name, err)
+		}
+	}
+	for _, route := range routes {
+		mapped, err := r.cfClient.Routes.InsertDestinations(ctx, route.GUID, []*cfv3resource.RouteDestinationInsertOrReplace{
+			{App: cfv3resource.RouteDestinationApp{GUID: &app.GUID}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to map route %s to the new app: %w", route.URL, err)
+		}
+		for _, destination := range mapped.Destinations {
+			if destination.GUID != nil && destination.App.GUID != nil && *destination.App.GUID == app.GUID {
+				destinations[route.GUID] = *destination.GUID
+			}
+		}
+	}
+
+	jobID, err := r.cfClient.Applications.Delete(ctx, previous.ID.ValueString())
+	if err != nil {
+		return nil, fmt.Errorf("unable to delete the old app %s: %w", previous.ID.ValueString(), err)
+	}
+	oldAppDeleted = true
+	if err = pollJob(ctx, *r.cfClient, jobID, defaultTimeout); err != nil {
+		return nil, fmt.Errorf("unable to verify the deletion of the old app %s: %w", previous.ID.ValueString(), err)
+	}
+	// routes of other spaces stay shared with the target space, the ones of the old space move with the app
+	for _, route := range routes {
+		if routeOwner(route) != oldSpace.GUID {
+			continue
+		}
+		if err = r.cfClient.Routes.TransferOwnership(ctx, route.GUID, space.GUID); err != nil {
+			return nil, fmt.Errorf("unable to transfer route %s to space %s: %w", route.URL, space.Name, err)
+		}
+		if err = r.cfClient.Routes.UnShareWithSpace(ctx, route.GUID, oldSpace.GUID); err != nil {
+			return nil, fmt.Errorf("unable to unshare route %s with space %s: %w", route.URL, oldSpace.Name, err)
+		}
+	}
+	return r.cfClient.Applications.Get(ctx, app.GUID)
+}
+
+// routeOwner returns the GUID of the space owning the route.
+func routeOwner(route *cfv3resource.Route) string {
+	if route.Relationships.Space.Data == nil {
+		return ""
+	}
+	return route.Relationships.Space.Data.GUID
+}
+
+// applyState starts, stops or restarts the app in place without pushing it again.
+func (r *appResource) applyState(ctx context.Context, desired AppType, previous AppType) (*cfv3resource.App, error) {
+	app, err := r.cfClient.Applications.Get(ctx, previous.ID.ValueString())
+	if err != nil {
+		return nil, err
+	}
+	desiredState := desired.State.ValueString()
+	if desired.State.IsNull() || desired.State.IsUnknown() {
+		desiredState = app.State
+	}
+	switch {
+	case desiredState == "STOPPED" && app.State != "STOPPED":
+		return r.cfClient.Applications.Stop(ctx, app.GUID)
+	case desiredState == "STARTED" && app.State != "STARTED":
+		return r.cfClient.Applications.Start(ctx, app.GUID)
+	case desiredState == "STARTED" && !desired.RestartTriggers.Equal(previous.RestartTriggers):
+		// cf deployments only know the rolling strategy, blue-green is emulated on push by the cf client
+		if desired.Strategy.IsNull() || desired.Strategy.ValueString() == "none" {
+			return r.cfClient.Applications.Restart(ctx, app.GUID)
+		}
+		return r.deploy(ctx, app, "")
+	}
+	return app, nil
+}
+
+// deploy rolls out the droplet with a rolling deployment, without a droplet the app is restarted with its current droplet.
+func (r *appResource) deploy(ctx context.Context, app *cfv3resource.App, dropletGUID string) (*cfv3resource.App, error) {
+	deploymentCreate := &cfv3resource.DeploymentCreate{
+		Relationships: cfv3resource.AppRelationship{
+			App: cfv3resource.ToOneRelationship{
+				Data: &cfv3resource.Relationship{GUID: app.GUID},
+			},
+		},
+	}
+	if dropletGUID != "" {
+		deploymentCreate.Droplet = &cfv3resource.Relationship{GUID: dropletGUID}
+	}
+	deployment, err := r.cfClient.Deployments.Create(ctx, deploymentCreate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deploy with: %s", err.Error())
+	}
+	pollOptions := cfv3client.NewPollingOptions()
+	pollOptions.Timeout = defaultTimeout
+	err = cfv3client.PollForStateOrTimeout(func() (string, error) {
+		deployment, err := r.cfClient.Deployments.Get(ctx, deployment.GUID)
+		if err != nil {
+			return "", err
+		}
+		return deployment.Status.Value, nil
+	}, "FINALIZED", pollOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify deployment of app %s: %w", app.Name, err)
+	}
+	return r.cfClient.Applications.Get(ctx, app.GUID)
+}
+
+// waitForApp waits for the app processes to reach the required number of running instances and runs the optional smoke check.
+// It fails right away once instances crash after the wait started, as a crash looping app does not become healthy by waiting.
+func (r *appResource) waitForApp(ctx context.Context, app *cfv3resource.App, waitFor *AppWaitFor, routes *cfv3operation.AppManifestRoutes) error {
+	timeout := defaultWaitForTimeout
+	if !waitFor.Timeout.IsNull() {
+		timeout = time.Duration(waitFor.Timeout.ValueInt64()) * time.Second
+	}
+	started := time.Now()
+	deadline := started.Add(timeout)
+	minInstances := map[string]int64{}
+	if !waitFor.MinRunningInstances.IsNull() {
+		if diags := waitFor.MinRunningInstances.ElementsAs(ctx, &minInstances, false); diags.HasError() {
+			return fmt.Errorf("invalid min_running_instances")
+		}
+	}
+
+	for {
+		healthy, crashed, summary, err := r.appInstancesHealthy(ctx, app.GUID, minInstances)
+		if err != nil {
+			return err
+		}
+		if healthy {
+			break
+		}
+		if crashed {
+			crashes, err := r.appCrashesSince(ctx, app.GUID, started)
+			if err != nil {
+				return err
+			}
+			if crashes != "" {
+				return fmt.Errorf("app %s is crashing:\n%s%s", app.Name, summary, crashes)
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for app %s:\n%s%s", timeout, app.Name, summary, r.recentAppEvents(ctx, app.GUID))
+		}
+		if err = sleepWithContext(ctx, appPollInterval); err != nil {
+			return err
+		}
+	}
+
+	if waitFor.SmokeCheck == nil {
+		return nil
+	}
+	url, err := smokeCheckURL(waitFor.SmokeCheck, routes)
+	if err != nil {
+		return err
+	}
+	for {
+		err = runSmokeCheck(ctx, r.cfClient.HTTPClient(), url, waitFor.SmokeCheck)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("smoke check against %s failed: %s%s", url, err.Error(), r.recentAppEvents(ctx, app.GUID))
+		}
+		if err = sleepWithContext(ctx, appPollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// appInstancesHealthy checks the instance states of all app processes and returns whether any instance crashed
+// together with a summary of unhealthy processes.
+func (r *appResource) appInstancesHealthy(ctx context.Context, appGUID string, minInstances map[string]int64) (bool, bool, string, error) {
+	processes, err := r.cfClient.Processes.ListForAppAll(ctx, appGUID, nil)
+	if err != nil {
+		return false, false, "", fmt.Errorf("unable to list processes: %w", err)
+	}
+	healthy := true
+	crashed := false
+	var summary strings.Builder
+	for _, process := range processes {
+		required, ok := minInstances[process.Type]
+		if !ok {
+			required = int64(process.Instances)
+		}
+		if required == 0 {
+			continue
+		}
+		stats, err := r.cfClient.Processes.GetStats(ctx, process.GUID)
+		if err != nil {
+			return false, false, "", fmt.Errorf("unable to fetch stats of process %s: %w", process.Type, err)
+		}
+		var running int64
+		for _, stat := range stats.Stats {
+			if stat.State == "RUNNING" {
+				running++
+				continue
+			}
+			if stat.State == "CRASHED" {
+				crashed = true
+			}
+			summary.WriteString(fmt.Sprintf("instance %d of process %s is %s", stat.Index, process.Type, stat.State))
+			if stat.Details != nil && *stat.Details != "" {
+				summary.WriteString(": " + *stat.Details)
+			}
+			summary.WriteString("\n")
+		}
+		if running < required {
+			healthy = false
+			summary.WriteString(fmt.Sprintf("process %s has %d of %d required instances running\n", process.Type, running, required))
+		}
+	}
+	return healthy, crashed, summary.String(), nil
+}
+
+// appCrashesSince describes the crashes of app instances since the given time with their reasons, or returns an empty
+// string if no instance crashed since then.
+func (r *appResource) appCrashesSince(ctx context.Context, appGUID string, since time.Time) (string, error) {
+	opts := cfv3client.NewAuditEventListOptions()
+	opts.TargetGUIDs.EqualTo(appGUID)
+	opts.Types.EqualTo("audit.app.process.crash")
+	opts.CreateAts.AfterOrEqualTo(since.Truncate(time.Second))
+	opts.OrderBy = "-created_at"
+	events, _, err := r.cfClient.AuditEvents.List(ctx, opts)
+	if err != nil {
+		return "", fmt.Errorf("unable to list crash events: %w", err)
+	}
+	if len(events) == 0 {
+		return "", nil
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n%d crashes since the deployment:\n", len(events)))
+	for _, event := range events {
+		b.WriteString(event.CreatedAt.Format(time.RFC3339))
+		if event.Data != nil {
+			var data struct {
+				Index           int    `json:"index"`
+				Reason          string `json:"reason"`
+				ExitDescription string `json:"exit_description"`
+			}
+			if json.Unmarshal(*event.Data, &data) == nil {
+				b.WriteString(fmt.Sprintf(" instance %d crashed (%s: %s)", data.Index, data.Reason, data.ExitDescription))
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// recentAppEvents returns the latest audit events of the app, such as crashes with their exit description.
+func (r *appResource) recentAppEvents(ctx context.Context, appGUID string) string {
+	opts := cfv3client.NewAuditEventListOptions()
+	opts.TargetGUIDs.EqualTo(appGUID)
+	opts.OrderBy = "-created_at"
+	opts.PerPage = 10
+	events, _, err := r.cfClient.AuditEvents.List(ctx, opts)
+	if err != nil || len(events) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\nRecent events:\n")
+	for _, event := range events {
+		b.WriteString(event.CreatedAt.Format(time.RFC3339) + " " + event.Type)
+		if event.Data != nil {
+			var data struct {
+				Reason          string `json:"reason"`
+				ExitDescription string `json:"exit_description"`
+			}
+			if json.Unmarshal(*event.Data, &data) == nil && (data.Reason != "" || data.ExitDescription != "") {
+				b.WriteString(fmt.Sprintf(" (%s: %s)", data.Reason, data.ExitDescription))
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// appFailureLogs returns the recent staging and app logs to enrich push and start failures, failures to fetch them are only logged.
+func (r *appResource) appFailureLogs(ctx context.Context, appType AppType) string {
+	appGUID := appType.ID.ValueString()
+	if appType.ID.IsUnknown() || appType.ID.IsNull() {
+		var err error
+		appGUID, err = findAppGUID(ctx, r.cfClient, appType.Org.ValueString(), appType.Space.ValueString(), appType.Name.ValueString())
+		if err != nil {
+			tflog.Debug(ctx, "unable to find app for failure logs: "+err.Error())
+			return ""
+		}
+	}
+	entries, err := readRecentAppLogs(ctx, r.cfClient, appGUID, []string{"STG", "APP"}, appFailureLogLines)
+	if err != nil {
+		tflog.Debug(ctx, "unable to read app logs: "+err.Error())
+		return ""
+	}
+	if len(entries) == 0 {
+		return ""
+	}
+	return "\nRecent logs:\n" + formatAppLogs(entries)
+}
+
+func smokeCheckURL(check *AppSmokeCheck, routes *cfv3operation.AppManifestRoutes) (string, error) {
+	route := check.Route.ValueString()
+	if check.Route.IsNull() {
+		if routes == nil || len(*routes) == 0 {
+			return "", fmt.Errorf("smoke check requires a route but the app has no routes mapped")
+		}
+		route = (*routes)[0].Route
+	}
+	if !strings.HasPrefix(route, "http://") && !strings.HasPrefix(route, "https://") {
+		route = "https://" + route
+	}
+	p := "/"
+	if !check.Path.IsNull() {
+		p = check.Path.ValueString()
+	}
+	return strings.TrimSuffix(route, "/") + "/" + strings.TrimPrefix(p, "/"), nil
+}
+
+// runSmokeCheck requests the URL with the given client, which should carry the TLS settings of the provider.
+func runSmokeCheck(ctx context.Context, client *http.Client, url string, check *AppSmokeCheck) error {
+	reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	expectedStatus := http.StatusOK
+	if !check.ExpectedStatus.IsNull() {
+		expectedStatus = int(check.ExpectedStatus.ValueInt64())
+	}
+	if resp.StatusCode != expectedStatus {
+		return fmt.Errorf("expected status %d but got %d", expectedStatus, resp.StatusCode)
+	}
+	if check.ExpectedBody.IsNull() {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	re, err := regexp.Compile(check.ExpectedBody.ValueString())
+	if err != nil {
+		return err
+	}
+	if !re.Match(body) {
+		return fmt.Errorf("response body does not match %q", check.ExpectedBody.ValueString())
+	}
+	return nil
+}
+
 func (r *appResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var appType AppType
 	diags := req.State.Get(ctx, &appType)
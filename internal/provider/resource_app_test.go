@@ -1,9 +1,20 @@
 package provider
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
 
+	cfv3operation "github.com/cloudfoundry/go-cfclient/v3/operation"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 )
 
 func TestAppResource_Configure(t *testing.T) {
@@ -66,6 +77,8 @@ EOT
 						resource.TestCheckResourceAttr(resourceName, "environment.MY_ENV", "red"),
 						resource.TestCheckResourceAttr(resourceName, "routes.0.route", "cf-sample-test.cfapps.sap.hana.ondemand.com"),
 						resource.TestCheckResourceAttr(resourceName, "routes.0.protocol", "http1"),
+						resource.TestCheckResourceAttr(resourceName, "lifecycle_type", "buildpack"),
+						resource.TestCheckResourceAttr(resourceName, "state", "STARTED"),
 					),
 				},
 			},
@@ -186,6 +199,7 @@ resource "cloudfoundry_app" "app" {
 						resource.TestCheckResourceAttr(resourceName, "processes.0.readiness_health_check_type", "http"),
 						resource.TestCheckResourceAttr(resourceName, "processes.0.readiness_health_check_http_endpoint", "/get"),
 						resource.TestCheckResourceAttr(resourceName, "processes.0.type", "web"),
+						resource.TestCheckResourceAttr(resourceName, "lifecycle_type", "docker"),
 					),
 				},
 			},
@@ -228,4 +242,831 @@ resource "cloudfoundry_app" "http-bin-sidecar" {
 			},
 		})
 	})
+	t.Run("happy path - create app with cnb lifecycle", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_app_cnb")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name           = "cf-nodejs-cnb"
+	space_name     = "tf-space-1"
+	org_name       = "PerformanceTeamBLR"
+	path           = "../../assets/cf-sample-app-nodejs.zip"
+	lifecycle_type = "cnb"
+	buildpacks     = ["docker://docker.io/paketobuildpacks/nodejs"]
+	memory         = "512M"
+	no_route       = true
+}
+					`,
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "name", "cf-nodejs-cnb"),
+						resource.TestCheckResourceAttr(resourceName, "lifecycle_type", "cnb"),
+						resource.TestCheckResourceAttr(resourceName, "buildpacks.#", "1"),
+						resource.TestCheckTypeSetElemAttr(resourceName, "buildpacks.*", "docker://docker.io/paketobuildpacks/nodejs"),
+						resource.TestCheckResourceAttr(resourceName, "state", "STARTED"),
+					),
+				},
+			},
+		})
+	})
+	t.Run("error path - create app with invalid lifecycle combinations", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_app_invalid_lifecycle")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name           = "cf-nodejs-cnb"
+	space_name     = "tf-space-1"
+	org_name       = "PerformanceTeamBLR"
+	path           = "../../assets/cf-sample-app-nodejs.zip"
+	lifecycle_type = "docker"
+}
+					`,
+					ExpectError: regexp.MustCompile(`Missing docker image`),
+				},
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name            = "cf-nodejs-cnb"
+	space_name      = "tf-space-1"
+	org_name        = "PerformanceTeamBLR"
+	path            = "../../assets/cf-sample-app-nodejs.zip"
+	cnb_credentials = jsonencode({ "registry.example.com" = { username = "user", password = "secret" } })
+}
+					`,
+					ExpectError: regexp.MustCompile(`Invalid attribute combination`),
+				},
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name           = "cf-nodejs-cnb"
+	space_name     = "tf-space-1"
+	org_name       = "PerformanceTeamBLR"
+	path           = "../../assets/cf-sample-app-nodejs.zip"
+	lifecycle_type = "cnb"
+	buildpacks     = ["docker://docker.io/paketobuildpacks/nodejs"]
+	strategy       = "blue-green"
+}
+					`,
+					ExpectError: regexp.MustCompile(`Invalid attribute combination`),
+				},
+			},
+		})
+	})
+	t.Run("error path - failed staging reports the recent logs", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_app_staging_failure")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name       = "cf-nodejs-staging-failure"
+	space_name = "tf-space-1"
+	org_name   = "PerformanceTeamBLR"
+	path       = "../../assets/cf-sample-app-nodejs.zip"
+	buildpacks = ["https://github.com/cloudfoundry/python-buildpack"]
+	no_route   = true
+}
+					`,
+					ExpectError: regexp.MustCompile(`(?s)Recent logs:.*STG/0`),
+				},
+			},
+		})
+	})
+	t.Run("happy path - rename app in place", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_app_rename")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name         = "cf-docker"
+	space_name   = "tf-space-1"
+	org_name     = "PerformanceTeamBLR"
+	docker_image = "cloudfoundry/diego-docker-app"
+	no_route     = true
+}
+					`,
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "name", "cf-docker"),
+					),
+				},
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name         = "cf-docker-renamed"
+	space_name   = "tf-space-1"
+	org_name     = "PerformanceTeamBLR"
+	docker_image = "cloudfoundry/diego-docker-app"
+	no_route     = true
+}
+					`,
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: []plancheck.PlanCheck{
+							plancheck.ExpectResourceAction(resourceName, plancheck.ResourceActionUpdate),
+						},
+					},
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "name", "cf-docker-renamed"),
+						resource.TestCheckResourceAttr(resourceName, "state", "STARTED"),
+					),
+				},
+			},
+		})
+	})
+	t.Run("happy path - move app to another space without downtime", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_app_space_move")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name                     = "cf-docker-move"
+	space_name               = "tf-space-1"
+	org_name                 = "PerformanceTeamBLR"
+	docker_image             = "cloudfoundry/diego-docker-app"
+	zero_downtime_space_move = true
+	routes = [
+		{
+			route = "cf-docker-move.cfapps.sap.hana.ondemand.com"
+		}
+	]
+}
+					`,
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "space_name", "tf-space-1"),
+					),
+				},
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name                     = "cf-docker-move"
+	space_name               = "tf-space-2"
+	org_name                 = "PerformanceTeamBLR"
+	docker_image             = "cloudfoundry/diego-docker-app"
+	zero_downtime_space_move = true
+	routes = [
+		{
+			route = "cf-docker-move.cfapps.sap.hana.ondemand.com"
+		}
+	]
+}
+					`,
+					ConfigPlanChecks: resource.ConfigPlanChecks{
+						PreApply: []plancheck.PlanCheck{
+							plancheck.ExpectResourceAction(resourceName, plancheck.ResourceActionUpdate),
+						},
+					},
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "space_name", "tf-space-2"),
+						resource.TestCheckResourceAttr(resourceName, "routes.0.route", "cf-docker-move.cfapps.sap.hana.ondemand.com"),
+						resource.TestCheckResourceAttr(resourceName, "state", "STARTED"),
+					),
+				},
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name                     = "cf-docker-move"
+	space_name               = "tf-space-1"
+	org_name                 = "PerformanceTeamBLR"
+	docker_image             = "cloudfoundry/does-not-exist"
+	zero_downtime_space_move = true
+	routes = [
+		{
+			route = "cf-docker-move.cfapps.sap.hana.ondemand.com"
+		}
+	]
+}
+					`,
+					ExpectError: regexp.MustCompile(`the old app is left untouched`),
+				},
+			},
+		})
+	})
+	t.Run("error path - create app with invalid wait_for settings", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_app_invalid_wait_for")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name       = "cf-nodejs"
+	space_name = "tf-space-1"
+	org_name   = "PerformanceTeamBLR"
+	path       = "../../assets/cf-sample-app-nodejs.zip"
+	wait_for = {
+		smoke_check = {
+			expected_body = "[unterminated"
+		}
+	}
+}
+					`,
+					ExpectError: regexp.MustCompile(`value must be a valid regular expression`),
+				},
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name       = "cf-nodejs"
+	space_name = "tf-space-1"
+	org_name   = "PerformanceTeamBLR"
+	path       = "../../assets/cf-sample-app-nodejs.zip"
+	wait_for = {
+		min_running_instances = { web = -1 }
+	}
+}
+					`,
+					ExpectError: regexp.MustCompile(`Invalid Attribute Value`),
+				},
+			},
+		})
+	})
+	t.Run("happy path - wait for running instances and smoke check", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_app_wait_for")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name       = "cf-nodejs-wait-for"
+	space_name = "tf-space-1"
+	org_name   = "PerformanceTeamBLR"
+	path       = "../../assets/cf-sample-app-nodejs.zip"
+	instances  = 2
+	routes = [
+		{
+			route = "cf-nodejs-wait-for.cfapps.sap.hana.ondemand.com"
+		}
+	]
+	wait_for = {
+		min_running_instances = { web = 2 }
+		timeout               = 300
+		smoke_check = {
+			path            = "/"
+			expected_status = 200
+			expected_body   = "Hello"
+		}
+	}
+}
+					`,
+					Check: resource.ComposeTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "state", "STARTED"),
+						resource.TestCheckResourceAttr(resourceName, "wait_for.min_running_instances.web", "2"),
+						resource.TestCheckResourceAttr(resourceName, "wait_for.smoke_check.expected_status", "200"),
+					),
+				},
+			},
+		})
+	})
+	t.Run("error path - crashing app fails without waiting for the timeout", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_app_crashing")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name       = "cf-nodejs-crashing"
+	space_name = "tf-space-1"
+	org_name   = "PerformanceTeamBLR"
+	path       = "../../assets/cf-sample-app-nodejs.zip"
+	command    = "exit 1"
+	no_route   = true
+	wait_for = {
+		timeout = 3600
+	}
+}
+					`,
+					ExpectError: regexp.MustCompile(`(?s)cf-nodejs-crashing is crashing.*CRASHED`),
+				},
+			},
+		})
+	})
+	t.Run("error path - create app with invalid manifest settings", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_app_invalid_manifest")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name       = "cf-nodejs"
+	space_name = "tf-space-1"
+	org_name   = "PerformanceTeamBLR"
+	path       = "../../assets/cf-sample-app-nodejs.zip"
+	vars = {
+		memory = "256M"
+	}
+}
+					`,
+					ExpectError: regexp.MustCompile(`Invalid attribute combination`),
+				},
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name       = "cf-nodejs"
+	space_name = "tf-space-1"
+	org_name   = "PerformanceTeamBLR"
+}
+					`,
+					ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+				},
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name       = "cf-nodejs"
+	space_name = "tf-space-1"
+	org_name   = "PerformanceTeamBLR"
+	manifest   = <<EOT
+applications:
+- name: cf-nodejs
+  path: ../../assets/cf-sample-app-nodejs.zip
+  memory: ((memory))
+  instances: ((instances))
+EOT
+	vars = {
+		instances = "2"
+	}
+}
+					`,
+					ExpectError: regexp.MustCompile(`expected to find variables: memory`),
+				},
+			},
+		})
+	})
+	t.Run("error path - create app with invalid state", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_app_invalid_state")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + `
+resource "cloudfoundry_app" "app" {
+	name       = "cf-nodejs"
+	space_name = "tf-space-1"
+	org_name   = "PerformanceTeamBLR"
+	path       = "../../assets/cf-sample-app-nodejs.zip"
+	state      = "RUNNING"
+}
+					`,
+					ExpectError: regexp.MustCompile(`Invalid Attribute Value Match`),
+				},
+			},
+		})
+	})
+}
+
+func TestSmokeCheckURL(t *testing.T) {
+	t.Parallel()
+	routes := &cfv3operation.AppManifestRoutes{
+		{Route: "cf-nodejs.cfapps.example.com"},
+		{Route: "cf-nodejs-internal.apps.internal"},
+	}
+	tests := []struct {
+		name    string
+		check   AppSmokeCheck
+		routes  *cfv3operation.AppManifestRoutes
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "first mapped route",
+			check:  AppSmokeCheck{Route: types.StringNull(), Path: types.StringNull()},
+			routes: routes,
+			want:   "https://cf-nodejs.cfapps.example.com/",
+		},
+		{
+			name:   "configured route and path",
+			check:  AppSmokeCheck{Route: types.StringValue("health.example.com/"), Path: types.StringValue("/healthz")},
+			routes: routes,
+			want:   "https://health.example.com/healthz",
+		},
+		{
+			name:  "configured scheme",
+			check: AppSmokeCheck{Route: types.StringValue("http://cf-nodejs.example.com"), Path: types.StringValue("ready")},
+			want:  "http://cf-nodejs.example.com/ready",
+		},
+		{
+			name:    "no routes",
+			check:   AppSmokeCheck{Route: types.StringNull(), Path: types.StringNull()},
+			routes:  &cfv3operation.AppManifestRoutes{},
+			wantErr: true,
+		},
+		{
+			name:    "no route configuration",
+			check:   AppSmokeCheck{Route: types.StringNull(), Path: types.StringValue("/healthz")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := smokeCheckURL(&tt.check, tt.routes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("smokeCheckURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("smokeCheckURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterpolateManifestVars(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		raw     string
+		vars    map[string]interface{}
+		want    string
+		wantErr string
+	}{
+		{
+			name: "scalar values",
+			raw:  "memory: ((memory))\ninstances: ((instances))\nroute: ((app-name)).((domains/internal))",
+			vars: map[string]interface{}{"memory": "256M", "instances": 2, "app-name": "cf-nodejs", "domains/internal": "apps.internal"},
+			want: "memory: 256M\ninstances: 2\nroute: cf-nodejs.apps.internal",
+		},
+		{
+			name: "complex values as flow style",
+			raw:  "env: ((env))\nbuildpacks: ((buildpacks))",
+			vars: map[string]interface{}{
+				"env":        map[interface{}]interface{}{"LOG_LEVEL": "debug", "FEATURES": map[interface{}]interface{}{"beta": true}},
+				"buildpacks": []interface{}{"nodejs_buildpack", map[interface{}]interface{}{"name": "extra"}},
+			},
+			want: "env: {\"FEATURES\":{\"beta\":true},\"LOG_LEVEL\":\"debug\"}\nbuildpacks: [\"nodejs_buildpack\",{\"name\":\"extra\"}]",
+		},
+		{
+			name:    "missing variables are reported once and sorted",
+			raw:     "memory: ((memory))\ninstances: ((instances))\nname: ((memory))-app",
+			vars:    map[string]interface{}{},
+			wantErr: "expected to find variables: instances, memory",
+		},
+		{
+			name: "text without placeholders",
+			raw:  "command: echo $((1+2)) (memory)",
+			vars: map[string]interface{}{},
+			want: "command: echo $((1+2)) (memory)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interpolateManifestVars(tt.raw, tt.vars)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("interpolateManifestVars() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("interpolateManifestVars() error = %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("interpolateManifestVars() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppType_LoadAppManifest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeFile := func(name string, content string) string {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	manifestPath := writeFile("manifest.yml", `applications:
+- name: cf-nodejs
+  path: app
+  memory: ((memory))
+  instances: ((instances))
+  disk_quota: ((disk))
+- name: cf-worker
+  memory: 64M
+`)
+	varsFiles := types.ListValueMust(types.StringType, []attr.Value{
+		types.StringValue(writeFile("vars.yml", "memory: 128M\ninstances: 1\ndisk: 512M\n")),
+		types.StringValue(writeFile("vars-prod.yml", "memory: 256M\ninstances: 3\n")),
+	})
+
+	t.Run("inline vars win over later vars files", func(t *testing.T) {
+		appType := AppType{
+			Name:         types.StringValue("cf-nodejs"),
+			Manifest:     types.StringNull(),
+			ManifestPath: types.StringValue(manifestPath),
+			VarsFiles:    varsFiles,
+			Vars:         types.MapValueMust(types.StringType, map[string]attr.Value{"instances": types.StringValue("5")}),
+		}
+		app, err := appType.loadAppManifest(ctx)
+		if err != nil {
+			t.Fatalf("loadAppManifest() error = %s", err)
+		}
+		if app.Memory != "256M" || app.DiskQuota != "512M" || app.Instances == nil || *app.Instances != 5 {
+			t.Errorf("loadAppManifest() memory = %s, disk_quota = %s, instances = %v, want 256M, 512M and 5", app.Memory, app.DiskQuota, app.Instances)
+		}
+		if app.Path != filepath.Join(dir, "app") {
+			t.Errorf("loadAppManifest() path = %s, want it relative to the manifest", app.Path)
+		}
+	})
+
+	t.Run("missing vars", func(t *testing.T) {
+		appType := AppType{
+			Name:         types.StringValue("cf-nodejs"),
+			Manifest:     types.StringNull(),
+			ManifestPath: types.StringValue(manifestPath),
+			VarsFiles:    types.ListNull(types.StringType),
+			Vars:         types.MapValueMust(types.StringType, map[string]attr.Value{"memory": types.StringValue("256M")}),
+		}
+		if _, err := appType.loadAppManifest(ctx); err == nil || !strings.Contains(err.Error(), "expected to find variables: disk, instances") {
+			t.Errorf("loadAppManifest() error = %v, want missing variables", err)
+		}
+	})
+
+	t.Run("single application takes the resource name", func(t *testing.T) {
+		appType := AppType{
+			Name:         types.StringValue("cf-nodejs-blue"),
+			Manifest:     types.StringValue("applications:\n- name: cf-nodejs\n  memory: 64M\n"),
+			ManifestPath: types.StringNull(),
+			VarsFiles:    types.ListNull(types.StringType),
+			Vars:         types.MapNull(types.StringType),
+		}
+		app, err := appType.loadAppManifest(ctx)
+		if err != nil {
+			t.Fatalf("loadAppManifest() error = %s", err)
+		}
+		if app.Name != "cf-nodejs-blue" || app.Memory != "64M" {
+			t.Errorf("loadAppManifest() = %s with memory %s, want cf-nodejs-blue with 64M", app.Name, app.Memory)
+		}
+	})
+
+	t.Run("application not in manifest", func(t *testing.T) {
+		appType := AppType{
+			Name:         types.StringValue("cf-unknown"),
+			Manifest:     types.StringNull(),
+			ManifestPath: types.StringValue(manifestPath),
+			VarsFiles:    varsFiles,
+			Vars:         types.MapNull(types.StringType),
+		}
+		if _, err := appType.loadAppManifest(ctx); err == nil || !strings.Contains(err.Error(), "application cf-unknown not found in manifest") {
+			t.Errorf("loadAppManifest() error = %v, want application not found", err)
+		}
+	})
+}
+
+func TestMergeAppManifests(t *testing.T) {
+	t.Parallel()
+	instances := func(i uint) *uint { return &i }
+	base := &cfv3operation.AppManifest{
+		Name:       "cf-nodejs",
+		Buildpacks: []string{"nodejs_buildpack"},
+		Env:        map[string]string{"LOG_LEVEL": "info", "REGION": "eu10"},
+		Routes:     &cfv3operation.AppManifestRoutes{{Route: "cf-nodejs.cfapps.example.com"}},
+		Stack:      "cflinuxfs3",
+		AppManifestProcess: cfv3operation.AppManifestProcess{
+			Memory:          "128M",
+			Instances:       instances(1),
+			HealthCheckType: cfv3operation.Http,
+		},
+	}
+	override := &cfv3operation.AppManifest{
+		Name:  "cf-nodejs",
+		Env:   map[string]string{"LOG_LEVEL": "debug"},
+		Stack: "cflinuxfs4",
+		AppManifestProcess: cfv3operation.AppManifestProcess{
+			Memory: "256M",
+		},
+	}
+
+	merged, err := mergeAppManifests(base, override)
+	if err != nil {
+		t.Fatalf("mergeAppManifests() error = %s", err)
+	}
+	want := &cfv3operation.AppManifest{
+		Name:       "cf-nodejs",
+		Buildpacks: []string{"nodejs_buildpack"},
+		// top level keys are replaced as a whole, like cf push does with flags
+		Env:    map[string]string{"LOG_LEVEL": "debug"},
+		Routes: &cfv3operation.AppManifestRoutes{{Route: "cf-nodejs.cfapps.example.com"}},
+		Stack:  "cflinuxfs4",
+		AppManifestProcess: cfv3operation.AppManifestProcess{
+			Memory:          "256M",
+			Instances:       instances(1),
+			HealthCheckType: cfv3operation.Http,
+		},
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("mergeAppManifests() =\n%+v\nwant\n%+v", merged, want)
+	}
+
+	// merging again with another manifest keeps applying later manifests on top
+	merged, err = mergeAppManifests(merged, &cfv3operation.AppManifest{Name: "cf-nodejs", Buildpacks: []string{"staticfile_buildpack"}})
+	if err != nil {
+		t.Fatalf("mergeAppManifests() error = %s", err)
+	}
+	if !slices.Equal(merged.Buildpacks, []string{"staticfile_buildpack"}) || merged.Memory != "256M" || merged.Stack != "cflinuxfs4" {
+		t.Errorf("mergeAppManifests() = %+v, want staticfile_buildpack with 256M on cflinuxfs4", merged)
+	}
+}
+
+func TestNormalizeAppManifest(t *testing.T) {
+	t.Parallel()
+	instances := uint(2)
+	tests := []struct {
+		name string
+		app  cfv3operation.AppManifest
+		want cfv3operation.AppManifest
+	}{
+		{
+			name: "app level process attributes move into web process",
+			app: cfv3operation.AppManifest{
+				Name:               "cf-nodejs",
+				AppManifestProcess: cfv3operation.AppManifestProcess{Memory: "256M", Instances: &instances, Command: "npm start"},
+			},
+			want: cfv3operation.AppManifest{
+				Name: "cf-nodejs",
+				Processes: &cfv3operation.AppManifestProcesses{
+					{Type: cfv3operation.Web, Memory: "256M", Instances: &instances, Command: "npm start"},
+				},
+			},
+		},
+		{
+			name: "explicit processes are kept",
+			app: cfv3operation.AppManifest{
+				Name:               "cf-nodejs",
+				Processes:          &cfv3operation.AppManifestProcesses{{Type: "worker", Memory: "64M"}},
+				AppManifestProcess: cfv3operation.AppManifestProcess{Memory: "256M"},
+			},
+			want: cfv3operation.AppManifest{
+				Name:               "cf-nodejs",
+				Processes:          &cfv3operation.AppManifestProcesses{{Type: "worker", Memory: "64M"}},
+				AppManifestProcess: cfv3operation.AppManifestProcess{Memory: "256M"},
+			},
+		},
+		{
+			name: "no process attributes",
+			app:  cfv3operation.AppManifest{Name: "cf-nodejs", Stack: "cflinuxfs4"},
+			want: cfv3operation.AppManifest{Name: "cf-nodejs", Stack: "cflinuxfs4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := tt.app
+			got := normalizeAppManifest(&app)
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("normalizeAppManifest() =\n%+v\nwant\n%+v", *got, tt.want)
+			}
+			if !reflect.DeepEqual(app, tt.app) {
+				t.Error("normalizeAppManifest() modified its input")
+			}
+		})
+	}
+}
+
+func TestAppType_RequiresPush(t *testing.T) {
+	t.Parallel()
+	newApp := func() AppType {
+		return AppType{
+			Name:            types.StringValue("cf-nodejs"),
+			Space:           types.StringValue("tf-space-1"),
+			Org:             types.StringValue("PerformanceTeamBLR"),
+			Path:            types.StringValue("../../assets/cf-sample-app-nodejs.zip"),
+			SourceCodeHash:  types.StringValue("1234"),
+			State:           types.StringValue("STARTED"),
+			RestartTriggers: types.MapValueMust(types.StringType, map[string]attr.Value{"config": types.StringValue("v1")}),
+			Memory:          types.StringValue("256M"),
+			Instances:       types.Int64Value(1),
+			Environment:     types.MapNull(types.StringType),
+			UpdatedAt:       types.StringValue("2026-10-18T10:00:00Z"),
+			Processes:       []Process{{Type: types.StringValue("web"), Memory: types.StringValue("256M")}},
+		}
+	}
+	tests := []struct {
+		name   string
+		modify func(plan *AppType)
+		want   bool
+	}{
+		{
+			name:   "no change",
+			modify: func(plan *AppType) {},
+			want:   false,
+		},
+		{
+			name: "state",
+			modify: func(plan *AppType) {
+				plan.State = types.StringValue("STOPPED")
+			},
+			want: false,
+		},
+		{
+			name: "restart triggers",
+			modify: func(plan *AppType) {
+				plan.RestartTriggers = types.MapValueMust(types.StringType, map[string]attr.Value{"config": types.StringValue("v2")})
+			},
+			want: false,
+		},
+		{
+			name: "name, wait_for and computed timestamps",
+			modify: func(plan *AppType) {
+				plan.Name = types.StringValue("cf-nodejs-renamed")
+				plan.WaitFor = &AppWaitFor{Timeout: types.Int64Value(60)}
+				plan.ZeroDowntimeSpaceMove = types.BoolValue(true)
+				plan.UpdatedAt = types.StringUnknown()
+			},
+			want: false,
+		},
+		{
+			name: "unknown source code hash",
+			modify: func(plan *AppType) {
+				plan.SourceCodeHash = types.StringUnknown()
+			},
+			want: false,
+		},
+		{
+			name: "source code hash",
+			modify: func(plan *AppType) {
+				plan.SourceCodeHash = types.StringValue("5678")
+			},
+			want: true,
+		},
+		{
+			name: "memory",
+			modify: func(plan *AppType) {
+				plan.Memory = types.StringValue("512M")
+			},
+			want: true,
+		},
+		{
+			name: "environment",
+			modify: func(plan *AppType) {
+				plan.Environment = types.MapValueMust(types.StringType, map[string]attr.Value{"LOG_LEVEL": types.StringValue("debug")})
+			},
+			want: true,
+		},
+		{
+			name: "process",
+			modify: func(plan *AppType) {
+				plan.Processes = []Process{{Type: types.StringValue("web"), Memory: types.StringValue("512M")}}
+			},
+			want: true,
+		},
+		{
+			name: "docker credentials",
+			modify: func(plan *AppType) {
+				plan.DockerCredentials = &DockerCredentials{Username: types.StringValue("user"), Password: types.StringValue("secret")}
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newApp()
+			plan := newApp()
+			tt.modify(&plan)
+			if got := plan.requiresPush(&state); got != tt.want {
+				t.Errorf("requiresPush() = %t, want %t", got, tt.want)
+			}
+		})
+	}
 }
@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 
@@ -12,6 +13,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -48,6 +51,18 @@ func (r *BuildpackResource) Schema(ctx context.Context, req resource.SchemaReque
 				MarkdownDescription: "The name of the stack that the buildpack will use",
 				Optional:            true,
 			},
+			"lifecycle_type": schema.StringAttribute{
+				MarkdownDescription: "The lifecycle of the buildpack. Use `cnb` to upload a Cloud Native Buildpack. Valid values are `buildpack` and `cnb`, defaults to `buildpack`.",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(buildpackLifecycle, cnbLifecycle),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
 			"position": schema.Int64Attribute{
 				MarkdownDescription: "The order in which the buildpacks are checked during buildpack auto-detection",
 				Optional:            true,
@@ -122,7 +137,8 @@ func (r *BuildpackResource) Create(ctx context.Context, req resource.CreateReque
 	createBuildpack, diags := plan.mapCreateBuildpackTypeToValues(ctx)
 	resp.Diagnostics.Append(diags...)
 
-	buildpack, err := r.cfClient.Buildpacks.Create(ctx, &createBuildpack)
+	var buildpack *buildpackWithLifecycle
+	err := cfRawRequest(ctx, r.cfClient, http.MethodPost, "/v3/buildpacks", &createBuildpack, &buildpack)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"API Error Creating Buildpack",
@@ -155,7 +171,7 @@ func (r *BuildpackResource) Create(ctx context.Context, req resource.CreateReque
 				)
 			}
 		}
-		buildpack, err = r.cfClient.Buildpacks.Get(ctx, buildpack.GUID)
+		buildpack, err = getBuildpack(ctx, r.cfClient, buildpack.GUID)
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"API Error Fetching Buildpack",
@@ -181,7 +197,7 @@ func (rs *BuildpackResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	buildpack, err := rs.cfClient.Buildpacks.Get(ctx, data.Id.ValueString())
+	buildpack, err := getBuildpack(ctx, rs.cfClient, data.Id.ValueString())
 	if err != nil {
 		handleReadErrors(ctx, resp, err, "buildpack", data.Id.ValueString())
 		return
@@ -207,7 +223,8 @@ func (rs *BuildpackResource) Update(ctx context.Context, req resource.UpdateRequ
 	updateBuildpack, diags := plan.mapUpdateBuildpackTypeToValues(ctx, previousState)
 	resp.Diagnostics.Append(diags...)
 
-	buildpack, err := rs.cfClient.Buildpacks.Update(ctx, plan.Id.ValueString(), &updateBuildpack)
+	var buildpack *buildpackWithLifecycle
+	err := cfRawRequest(ctx, rs.cfClient, http.MethodPatch, "/v3/buildpacks/"+plan.Id.ValueString(), &updateBuildpack, &buildpack)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"API Error Updating Buildpack",
@@ -240,7 +257,7 @@ func (rs *BuildpackResource) Update(ctx context.Context, req resource.UpdateRequ
 				"Failed in uploading the Buildpack with file "+fileName+" : "+err.Error(),
 			)
 		}
-		buildpack, err = rs.cfClient.Buildpacks.Get(ctx, buildpack.GUID)
+		buildpack, err = getBuildpack(ctx, rs.cfClient, buildpack.GUID)
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"API Error Fetching Buildpack",
@@ -286,6 +303,16 @@ func (rs *BuildpackResource) Delete(ctx context.Context, req resource.DeleteRequ
 	tflog.Trace(ctx, "deleted a buildpack resource")
 }
 
+// Fetches the buildpack including its lifecycle which is not part of the cf-client buildpack resource.
+func getBuildpack(ctx context.Context, client *cfv3client.Client, guid string) (*buildpackWithLifecycle, error) {
+	var buildpack *buildpackWithLifecycle
+	err := cfRawRequest(ctx, client, http.MethodGet, "/v3/buildpacks/"+guid, nil, &buildpack)
+	if err != nil {
+		return nil, err
+	}
+	return buildpack, nil
+}
+
 func (rs *BuildpackResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
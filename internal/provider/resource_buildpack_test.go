@@ -18,6 +18,7 @@ type BuildpackModelPtr struct {
 	State         *string
 	Id            *string
 	Stack         *string
+	Lifecycle     *string
 	Filename      *string
 	Position      *int
 	Enabled       *bool
@@ -47,6 +48,9 @@ func hclBuildpack(bmp *BuildpackModelPtr) string {
 			{{if .Stack}}
 				stack = "{{.Stack}}"
 			{{- end -}}
+			{{if .Lifecycle}}
+				lifecycle_type = "{{.Lifecycle}}"
+			{{- end -}}
 			{{if .Filename}}
 				filename = {{.Filename}}
 			{{- end -}}
@@ -133,6 +137,7 @@ func TestBuildpackResource_Configure(t *testing.T) {
 						resource.TestCheckResourceAttr(resourceName, "name", buildpackName),
 						resource.TestCheckResourceAttr(resourceName, "path", zipFilePath),
 						resource.TestCheckResourceAttr(resourceName, "labels.purpose", "testing"),
+						resource.TestCheckResourceAttr(resourceName, "lifecycle_type", "buildpack"),
 					),
 				},
 				{
@@ -169,6 +174,39 @@ func TestBuildpackResource_Configure(t *testing.T) {
 			},
 		})
 	})
+	t.Run("happy path - create cnb buildpack", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_buildpack_cnb")
+		defer stopQuietly(rec)
+
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + hclBuildpack(&BuildpackModelPtr{
+						HclType:       hclObjectResource,
+						HclObjectName: "rs",
+						Name:          strtostrptr("hifi-cnb"),
+						Lifecycle:     strtostrptr("cnb"),
+						Enabled:       &enabled,
+					}),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestMatchResourceAttr(resourceName, "id", regexpValidUUID),
+						resource.TestCheckResourceAttr(resourceName, "name", "hifi-cnb"),
+						resource.TestCheckResourceAttr(resourceName, "lifecycle_type", "cnb"),
+					),
+				},
+				{
+					ResourceName:            resourceName,
+					ImportStateIdFunc:       getIdForImport(resourceName),
+					ImportStateVerifyIgnore: []string{"path", "source_code_hash"},
+					ImportState:             true,
+					ImportStateVerify:       true,
+				},
+			},
+		})
+	})
 	t.Run("error path - create/update buildpacks with existing name/invalid path/invalid zip file", func(t *testing.T) {
 		cfg := getCFHomeConf()
 		rec := cfg.SetupVCR(t, "fixtures/resource_buildpack_invalid")
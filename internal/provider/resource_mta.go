@@ -1,13 +1,26 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/SAP/terraform-provider-cloudfoundry/internal/mta"
 	"github.com/SAP/terraform-provider-cloudfoundry/internal/provider/managers"
 	"github.com/SAP/terraform-provider-cloudfoundry/internal/validation"
+	cfv3client "github.com/cloudfoundry/go-cfclient/v3/client"
+	cfv3resource "github.com/cloudfoundry/go-cfclient/v3/resource"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/boolvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
@@ -23,8 +36,21 @@ import (
 )
 
 var (
-	_ resource.Resource              = &mtaResource{}
-	_ resource.ResourceWithConfigure = &mtaResource{}
+	_ resource.Resource                   = &mtaResource{}
+	_ resource.ResourceWithConfigure      = &mtaResource{}
+	_ resource.ResourceWithValidateConfig = &mtaResource{}
+	_ resource.ResourceWithModifyPlan     = &mtaResource{}
+)
+
+const (
+	mtaDefaultStrategy              = "default"
+	mtaBlueGreenStrategy            = "blue-green"
+	mtaIncrementalBlueGreenStrategy = "incremental-blue-green"
+
+	mtaDefaultTimeout = 60 * time.Minute
+	mtaLogTailLines   = 30
+	// Size in MB above which archives are uploaded in parts, as done by the MultiApps CF CLI plugin.
+	mtaDefaultUploadChunkSize = 45
 )
 
 func NewMtaResource() resource.Resource {
@@ -32,14 +58,16 @@ func NewMtaResource() resource.Resource {
 }
 
 type mtaResource struct {
-	mtaClient *mta.APIClient
+	mtaClient    *mta.APIClient
+	mtaClientErr error
+	cfClient     *cfv3client.Client
 }
 
 func (r *mtaResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_mta"
 }
 
-func (r *mtaResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *mtaResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: `Allows deploying applications and services via an MTAR archive or URL.
 		
@@ -48,12 +76,13 @@ __Further documentation:__
 `,
 		Attributes: map[string]schema.Attribute{
 			"mtar_path": schema.StringAttribute{
-				MarkdownDescription: "The local path where the MTA archive is present. Either this attribute or mtar_url need to be set.",
+				MarkdownDescription: "The local path where the MTA archive is present. Exactly one of mtar_path, mtar_url or mta_directory needs to be set.",
 				Optional:            true,
 				Validators: []validator.String{
 					stringvalidator.ExactlyOneOf(path.Expressions{
 						path.MatchRoot("mtar_path"),
 						path.MatchRoot("mtar_url"),
+						path.MatchRoot("mta_directory"),
 					}...),
 				},
 			},
@@ -61,6 +90,43 @@ __Further documentation:__
 				MarkdownDescription: "The remote URL where the MTA archive is present",
 				Optional:            true,
 			},
+			"mta_directory": schema.StringAttribute{
+				MarkdownDescription: "A local directory containing the deployment descriptor in `META-INF/mtad.yaml` or `mtad.yaml` and the paths of its modules and resources. The MTA archive is built from it on apply.",
+				Optional:            true,
+			},
+			"deployment_preview": schema.SingleNestedAttribute{
+				MarkdownDescription: "A preview of the changes of the next deployment, read from the deployment descriptor of mtar_path or mta_directory and compared with the deployed MTA. It is only set in the plan of a deployment and cleared when the MTA is read again.",
+				Computed:            true,
+				Attributes: map[string]schema.Attribute{
+					"version": schema.StringAttribute{
+						MarkdownDescription: "The version of the MTA to deploy.",
+						Computed:            true,
+					},
+					"deployed_version": schema.StringAttribute{
+						MarkdownDescription: "The version of the MTA deployed before.",
+						Computed:            true,
+					},
+					"added_modules": schema.ListAttribute{
+						MarkdownDescription: "The modules which are deployed for the first time.",
+						Computed:            true,
+						ElementType:         types.StringType,
+					},
+					"removed_modules": schema.ListAttribute{
+						MarkdownDescription: "The deployed modules which are no longer part of the MTA.",
+						Computed:            true,
+						ElementType:         types.StringType,
+					},
+					"created_services": schema.ListAttribute{
+						MarkdownDescription: "The services which are created by the deployment.",
+						Computed:            true,
+						ElementType:         types.StringType,
+					},
+				},
+			},
+			"mta_directory_hash": schema.StringAttribute{
+				MarkdownDescription: "The SHA256 hash of the deployment descriptor and of the files mta_directory is archived from, used to detect changes of its content. The archive is only built when applying, which fails if the content changed after planning.",
+				Computed:            true,
+			},
 			"extension_descriptors": schema.SetAttribute{
 				MarkdownDescription: "The paths for the MTA deployment extension files.",
 				Optional:            true,
@@ -69,6 +135,14 @@ __Further documentation:__
 					setvalidator.SizeAtLeast(1),
 				},
 			},
+			"extension_descriptor_content": schema.ListAttribute{
+				MarkdownDescription: "The contents of MTA deployment extension descriptors in YAML format. They are applied after the ones in extension_descriptors.",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
 			"space": schema.StringAttribute{
 				MarkdownDescription: "The GUID of the space where the MTA will be deployed",
 				Required:            true,
@@ -80,7 +154,7 @@ __Further documentation:__
 				},
 			},
 			"deploy_url": schema.StringAttribute{
-				MarkdownDescription: "The URL of the deploy service, if a custom one has been used(should be present in the same landscape). By default 'deploy-service.<system-domain>'",
+				MarkdownDescription: "The URL of the deploy service, if a custom one has been used(should be present in the same landscape). By default the deploy service URL of the provider is used",
 				Optional:            true,
 			},
 			"namespace": schema.StringAttribute{
@@ -90,11 +164,158 @@ __Further documentation:__
 					stringplanmodifier.RequiresReplaceIfConfigured(),
 				},
 			},
+			"modules": schema.SetAttribute{
+				MarkdownDescription: "The names of the modules to deploy. By default all modules of the MTA are deployed.",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"resources": schema.SetAttribute{
+				MarkdownDescription: "The names of the resources to process. By default all resources of the MTA are processed.",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"version_rule": schema.StringAttribute{
+				MarkdownDescription: "The rule comparing the version of the MTA to the deployed one which decides if it is deployed. One of `HIGHER`, `SAME_HIGHER` or `ALL`. Defaults to `SAME_HIGHER` on the deploy service.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("HIGHER", "SAME_HIGHER", "ALL"),
+				},
+			},
+			"keep_files": schema.BoolAttribute{
+				MarkdownDescription: "Keep the uploaded archive and extension descriptors on the deploy service after the deployment.",
+				Optional:            true,
+			},
+			"no_restart_subscribed_apps": schema.BoolAttribute{
+				MarkdownDescription: "Do not restart the applications subscribed to the configuration entries provided by the MTA.",
+				Optional:            true,
+			},
+			"delete_service_keys": schema.BoolAttribute{
+				MarkdownDescription: "Delete the service keys which are no longer part of the MTA.",
+				Optional:            true,
+			},
+			"delete_service_brokers": schema.BoolAttribute{
+				MarkdownDescription: "Delete the service brokers which are no longer part of the MTA.",
+				Optional:            true,
+			},
+			"abort_on_error": schema.BoolAttribute{
+				MarkdownDescription: "Abort the deploy operation on errors instead of leaving it in an error state for a retry.",
+				Optional:            true,
+			},
+			"additional_parameters": schema.MapAttribute{
+				MarkdownDescription: "Further parameters of the deploy operation which are passed to the deploy service as they are, e.g. `gitUri`, `gitRef` or `gitRepoPath`.",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.Map{
+					mapvalidator.SizeAtLeast(1),
+				},
+			},
+			"undeploy": schema.SingleNestedAttribute{
+				MarkdownDescription: "Settings for undeploying the MTA when the resource is destroyed. Without this block, the MTA is undeployed together with its services. Destroying the MTA fails if a service to be deleted is still bound to an application outside of the MTA.",
+				Optional:            true,
+				Attributes: map[string]schema.Attribute{
+					"delete_services": schema.BoolAttribute{
+						MarkdownDescription: "Delete the services of the MTA. Defaults to true.",
+						Optional:            true,
+					},
+					"delete_service_keys": schema.BoolAttribute{
+						MarkdownDescription: "Delete the service keys of the MTA.",
+						Optional:            true,
+					},
+					"delete_service_brokers": schema.BoolAttribute{
+						MarkdownDescription: "Delete the service brokers of the MTA.",
+						Optional:            true,
+					},
+					"keep_on_destroy": schema.BoolAttribute{
+						MarkdownDescription: "Only remove the MTA from the Terraform state on destroy and keep it deployed.",
+						Optional:            true,
+					},
+				},
+			},
 			//i walk along an empty street on the boulevard of broken dreams
 			"source_code_hash": schema.StringAttribute{
 				MarkdownDescription: "SHA256 hash of the file specified. Terraform relies on this to detect the file changes.",
 				Optional:            true,
 			},
+			"deploy_strategy": schema.StringAttribute{
+				MarkdownDescription: "The strategy used to deploy the MTA. `blue-green` deploys new versions of the applications next to the running ones and switches the routes over once they are started, `incremental-blue-green` additionally scales the new applications up instance by instance while scaling the old ones down. Defaults to `default`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(mtaDefaultStrategy, mtaBlueGreenStrategy, mtaIncrementalBlueGreenStrategy),
+				},
+			},
+			"blue_green": schema.SingleNestedAttribute{
+				MarkdownDescription: "Settings for the `blue-green` and `incremental-blue-green` deploy strategies. Unless a smoke check or manual confirmation is configured, the testing phase is skipped and the new applications take over the routes right away.",
+				Optional:            true,
+				Attributes: map[string]schema.Attribute{
+					"skip_idle_start": schema.BoolAttribute{
+						MarkdownDescription: "Do not start the new applications on their idle routes before switching the productive routes to them.",
+						Optional:            true,
+					},
+					"manual_confirmation": schema.BoolAttribute{
+						MarkdownDescription: "Stop at the testing phase and wait until the operation is resumed or aborted outside of Terraform, e.g. with `cf deploy -i <operation-id> -a resume`.",
+						Optional:            true,
+						Validators: []validator.Bool{
+							boolvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("smoke_check")),
+						},
+					},
+					"smoke_check": schema.SingleNestedAttribute{
+						MarkdownDescription: "An HTTP request against the idle applications at the testing phase. The operation is resumed if it succeeds and aborted otherwise.",
+						Optional:            true,
+						Attributes: map[string]schema.Attribute{
+							"url": schema.StringAttribute{
+								MarkdownDescription: "The URL to request, usually pointing to the idle route of an application.",
+								Required:            true,
+								Validators: []validator.String{
+									stringvalidator.RegexMatches(regexp.MustCompile(`^https?://`), "must be an http or https URL"),
+								},
+							},
+							"expected_status": schema.Int64Attribute{
+								MarkdownDescription: "The expected HTTP status code of the response. Defaults to 200.",
+								Optional:            true,
+								Validators: []validator.Int64{
+									int64validator.Between(100, 599),
+								},
+							},
+							"expected_body": schema.StringAttribute{
+								MarkdownDescription: "A regular expression the response body has to match.",
+								Optional:            true,
+								Validators: []validator.String{
+									validation.ValidRegex(),
+								},
+							},
+						},
+					},
+				},
+			},
+			"abort_on_timeout": schema.BoolAttribute{
+				MarkdownDescription: "Whether to abort the running MTA operation when a timeout is reached or the apply is interrupted. Set to false to leave the operation running on the deploy service. Defaults to true.",
+				Optional:            true,
+			},
+			"upload_chunk_size": schema.Int64Attribute{
+				MarkdownDescription: "Size in MB above which the MTA archive is split into parts of this size, which are uploaded separately and retried individually on failure. Defaults to 45.",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"logs_directory": schema.StringAttribute{
+				MarkdownDescription: "A local directory to write the logs of the deploy service operations to. The logs of every operation are written to a `mta-op-<operation-id>` subdirectory.",
+				Optional:            true,
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Create:            true,
+				CreateDescription: "Timeout for deploying the MTA. Default is 60 minutes",
+				Update:            true,
+				UpdateDescription: "Timeout for redeploying the MTA. Default is 60 minutes",
+				Delete:            true,
+				DeleteDescription: "Timeout for undeploying the MTA. Default is 60 minutes",
+			}),
 			"id": schema.StringAttribute{
 				MarkdownDescription: "The MTA ID of the deployment",
 				Computed:            true,
@@ -173,23 +394,215 @@ func (r *mtaResource) Configure(ctx context.Context, req resource.ConfigureReque
 		return
 	}
 
-	apiEndpointURL := session.CFClient.ApiURL("")
-	conf := mta.NewConfiguration(apiEndpointURL, session.CFClient.UserAgent(), session.CFClient.HTTPAuthClient())
+	// The URL is only required if no deploy_url is set, so it fails on use
+	deployServiceURL, err := session.DeployServiceURL(ctx)
+	conf := mta.NewConfiguration(deployServiceURL, session.CFClient.UserAgent(), session.CFClient.HTTPAuthClient())
 	r.mtaClient = mta.NewAPIClient(conf)
+	r.mtaClientErr = err
+	r.cfClient = session.CFClient
+}
+
+func (r *mtaResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config MtarType
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.BlueGreen != nil && !config.DeployStrategy.IsUnknown() && !isBlueGreenStrategy(config.DeployStrategy) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("blue_green"),
+			"Invalid Attribute Combination",
+			"blue_green can only be set if deploy_strategy is blue-green or incremental-blue-green",
+		)
+	}
+
+	// The archive or directory may not exist yet at validation time, a missing one is reported on apply
+	var descriptor *mta.MtaDescriptor
+	if !config.MtarPath.IsNull() && !config.MtarPath.IsUnknown() {
+		if archiveDescriptor, err := mta.GetMtaDescriptorFromArchive(config.MtarPath.ValueString()); err == nil {
+			descriptor = &archiveDescriptor
+		}
+	}
+	if !config.MtaDirectory.IsNull() && !config.MtaDirectory.IsUnknown() {
+		if directoryDescriptor, err := mta.GetMtaDescriptorFromDirectory(config.MtaDirectory.ValueString()); err == nil {
+			descriptor = &directoryDescriptor
+		}
+	}
+
+	if descriptor != nil {
+		validateMtaDescriptorNames(ctx, config.Modules, descriptor.ModuleNames(), path.Root("modules"), "module", descriptor.ID, &resp.Diagnostics)
+		validateMtaDescriptorNames(ctx, config.Resources, descriptor.ResourceNames(), path.Root("resources"), "resource", descriptor.ID, &resp.Diagnostics)
+	}
+	validateMtaExtensionDescriptors(ctx, config, descriptor, &resp.Diagnostics)
+}
+
+// Parses the configured extension descriptors and checks them against the base descriptor if it is available.
+func validateMtaExtensionDescriptors(ctx context.Context, config MtarType, descriptor *mta.MtaDescriptor, diags *diag.Diagnostics) {
+	type extensionDescriptor struct {
+		extension     mta.MtaExtensionDescriptor
+		attributePath path.Path
+		source        string
+	}
+	var extensions []extensionDescriptor
+	add := func(content []byte, attributePath path.Path, source string) {
+		extension, err := mta.ParseMtaExtensionDescriptor(content)
+		if err != nil {
+			diags.AddAttributeError(attributePath, "Invalid MTA Extension Descriptor", fmt.Sprintf("%s is not a valid extension descriptor: %s", source, err.Error()))
+			return
+		}
+		extensions = append(extensions, extensionDescriptor{extension: extension, attributePath: attributePath, source: source})
+	}
+
+	if !config.ExtensionDescriptors.IsNull() && !config.ExtensionDescriptors.IsUnknown() {
+		var locations []types.String
+		diags.Append(config.ExtensionDescriptors.ElementsAs(ctx, &locations, false)...)
+		for _, location := range locations {
+			if location.IsUnknown() {
+				continue
+			}
+			// Unreadable files are reported on upload
+			content, err := os.ReadFile(location.ValueString())
+			if err != nil {
+				continue
+			}
+			add(content, path.Root("extension_descriptors"), location.ValueString())
+		}
+	}
+	if !config.ExtensionDescriptorContent.IsNull() && !config.ExtensionDescriptorContent.IsUnknown() {
+		var contents []types.String
+		diags.Append(config.ExtensionDescriptorContent.ElementsAs(ctx, &contents, false)...)
+		for i, content := range contents {
+			if content.IsUnknown() {
+				continue
+			}
+			add([]byte(content.ValueString()), path.Root("extension_descriptor_content").AtListIndex(i), fmt.Sprintf("extension_descriptor_content[%d]", i))
+		}
+	}
 
-	subDomainWithProtocol := strings.Split(apiEndpointURL, ".")[0]
-	subDomain := strings.Split(subDomainWithProtocol, "//")[1]
-	deploySubdomainWithProtocol := strings.Replace(subDomainWithProtocol, subDomain, "deploy-service", 1)
-	deployURL := strings.Replace(apiEndpointURL, subDomainWithProtocol, deploySubdomainWithProtocol, 1)
+	if descriptor == nil {
+		return
+	}
+	extendableIds := []string{descriptor.ID}
+	for _, extension := range extensions {
+		extendableIds = append(extendableIds, extension.extension.ID)
+	}
+	for _, extension := range extensions {
+		if err := extension.extension.Validate(*descriptor, extendableIds); err != nil {
+			diags.AddAttributeError(extension.attributePath, "Invalid MTA Extension Descriptor", fmt.Sprintf("%s does not match the deployment descriptor: %s", extension.source, err.Error()))
+		}
+	}
+}
+
+// Reports the names of a set attribute which are not part of the MTA descriptor.
+func validateMtaDescriptorNames(ctx context.Context, names types.Set, known []string, attributePath path.Path, kind string, mtaId string, diags *diag.Diagnostics) {
+	if names.IsNull() || names.IsUnknown() {
+		return
+	}
+	var configured []string
+	diags.Append(names.ElementsAs(ctx, &configured, false)...)
+	for _, name := range configured {
+		if !slices.Contains(known, name) {
+			diags.AddAttributeError(
+				attributePath,
+				"Invalid Attribute Value",
+				fmt.Sprintf("%s %q is not part of MTA %s, known names are: %s", kind, name, mtaId, strings.Join(known, ", ")),
+			)
+		}
+	}
+}
+
+func (r *mtaResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	var plan, state MtarType
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var descriptor *mta.MtaDescriptor
+	if !plan.MtaDirectory.IsUnknown() {
+		plan.MtaDirectoryHash = types.StringNull()
+	}
+	if !plan.MtaDirectory.IsNull() && !plan.MtaDirectory.IsUnknown() {
+		// the archive itself is only built when applying
+		directoryHash, directoryDescriptor, err := mta.HashMtaDirectory(plan.MtaDirectory.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("mta_directory"),
+				"Unable to read MTA directory",
+				fmt.Sprintf("Reading the MTA directory %s failed with %s", plan.MtaDirectory.ValueString(), err.Error()),
+			)
+			return
+		}
+		plan.MtaDirectoryHash = types.StringValue(directoryHash)
+		descriptor = &directoryDescriptor
+	}
+	if !plan.MtarPath.IsNull() && !plan.MtarPath.IsUnknown() {
+		if archiveDescriptor, err := mta.GetMtaDescriptorFromArchive(plan.MtarPath.ValueString()); err == nil {
+			descriptor = &archiveDescriptor
+		}
+	}
+
+	// Only preview a deployment if one is going to happen
+	plan.DeploymentPreview = types.ObjectNull(mtaDeploymentPreviewAttributes)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		plan.DeploymentPreview = state.DeploymentPreview
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+		if resp.Diagnostics.HasError() || resp.Plan.Raw.Equal(req.State.Raw) {
+			return
+		}
+		plan.DeploymentPreview = types.ObjectNull(mtaDeploymentPreviewAttributes)
+	}
+	if descriptor != nil {
+		var diags diag.Diagnostics
+		plan.DeploymentPreview, diags = buildMtaDeploymentPreview(ctx, *descriptor, state.Mta, plan.Modules)
+		resp.Diagnostics.Append(diags...)
+	}
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
 
-	r.mtaClient.ChangeBasePath(deployURL)
+func isBlueGreenStrategy(strategy types.String) bool {
+	return strategy.ValueString() == mtaBlueGreenStrategy || strategy.ValueString() == mtaIncrementalBlueGreenStrategy
 }
 
 func (r *mtaResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan MtarType
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	createTimeout, diags := plan.Timeouts.Create(ctx, mtaDefaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
 	r.upsert(ctx, &req.Plan, nil, &resp.State, &resp.Diagnostics)
 }
 
 func (r *mtaResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan MtarType
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	updateTimeout, diags := plan.Timeouts.Update(ctx, mtaDefaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
 	r.upsert(ctx, &req.Plan, &req.State, &resp.State, &resp.Diagnostics)
 }
 
@@ -211,14 +624,19 @@ func (r *mtaResource) upsert(ctx context.Context, reqPlan *tfsdk.Plan, reqState
 	spaceGuid := mtarType.Space.ValueString()
 	namespace := mtarType.Namespace.ValueString()
 
-	if !mtarType.DeployUrl.IsNull() {
-		r.mtaClient.ChangeBasePath(mtarType.DeployUrl.ValueString())
+	mtaClient, err := mtaClientForDeployUrl(r.mtaClient, r.mtaClientErr, mtarType.DeployUrl)
+	if err != nil {
+		respDiags.AddError(
+			"Unable to create deploy service client",
+			err.Error(),
+		)
+		return
 	}
 
 	if !mtarType.MtarPath.IsNull() {
 		fileLocation := mtarType.MtarPath.ValueString()
 
-		uploadedFile, _, err = r.mtaClient.DefaultApi.UploadMtaFile(ctx, spaceGuid, namespace, fileLocation)
+		uploadedFile, err = mta.UploadMtaArchive(ctx, mtaClient, spaceGuid, namespace, fileLocation, mtarType.uploadChunkSize())
 		if err != nil {
 			respDiags.AddError(
 				"Unable to upload mtar file",
@@ -239,9 +657,46 @@ func (r *mtaResource) upsert(ctx context.Context, reqPlan *tfsdk.Plan, reqState
 		mtaId = descriptor.ID
 	}
 
+	if !mtarType.MtaDirectory.IsNull() {
+		directoryHash, _, err := mta.HashMtaDirectory(mtarType.MtaDirectory.ValueString())
+		if err != nil {
+			respDiags.AddError(
+				"Unable to read MTA directory",
+				fmt.Sprintf("Reading the MTA directory %s failed with %s ", mtarType.MtaDirectory.ValueString(), err.Error()),
+			)
+			return
+		}
+		if mtarType.MtaDirectoryHash.IsUnknown() {
+			mtarType.MtaDirectoryHash = types.StringValue(directoryHash)
+		} else if directoryHash != mtarType.MtaDirectoryHash.ValueString() {
+			respDiags.AddError(
+				"MTA directory changed after planning",
+				fmt.Sprintf("The content of %s has the hash %s instead of the planned %s. Run terraform plan again to deploy the current content.", mtarType.MtaDirectory.ValueString(), directoryHash, mtarType.MtaDirectoryHash.ValueString()),
+			)
+			return
+		}
+		archive, descriptor, err := mta.BuildMtaArchive(mtarType.MtaDirectory.ValueString())
+		if err != nil {
+			respDiags.AddError(
+				"Unable to build MTA archive",
+				fmt.Sprintf("Building the MTA archive from %s failed with %s ", mtarType.MtaDirectory.ValueString(), err.Error()),
+			)
+			return
+		}
+		uploadedFile, err = mta.UploadMtaArchiveContent(ctx, mtaClient, spaceGuid, namespace, descriptor.ID+".mtar", bytes.NewReader(archive), int64(len(archive)), mtarType.uploadChunkSize())
+		if err != nil {
+			respDiags.AddError(
+				"Unable to upload mtar file",
+				fmt.Sprintf("Request failed with %s ", err.Error()),
+			)
+			return
+		}
+		mtaId = descriptor.ID
+	}
+
 	if !mtarType.MtarUrl.IsNull() {
 		fileLocation := mtarType.MtarUrl.ValueString()
-		uploadJobID, uploadResp, err := r.mtaClient.DefaultApi.AsyncUploadFileFromURL(ctx, spaceGuid, namespace, fileLocation)
+		uploadJobID, uploadResp, err := mtaClient.DefaultApi.AsyncUploadFileFromURL(ctx, spaceGuid, namespace, fileLocation)
 		if err != nil {
 			respDiags.AddError(
 				"Unable to upload remote mtar file",
@@ -250,7 +705,7 @@ func (r *mtaResource) upsert(ctx context.Context, reqPlan *tfsdk.Plan, reqState
 			return
 		}
 
-		jobResponse, err := mta.PollMtaJob(ctx, r.mtaClient, spaceGuid, uploadJobID, mta.FinishedState, uploadResp.Header.Get("x-cf-app-instance"), namespace)
+		jobResponse, err := mta.PollMtaJob(ctx, mtaClient, spaceGuid, uploadJobID, mta.FinishedState, uploadResp.Header.Get("x-cf-app-instance"), namespace)
 		if err != nil {
 			respDiags.AddError(
 				"Unable to poll MTAR upload job",
@@ -274,16 +729,32 @@ func (r *mtaResource) upsert(ctx context.Context, reqPlan *tfsdk.Plan, reqState
 		}
 	}
 
+	var extensionFileID []string
 	if !mtarType.ExtensionDescriptors.IsNull() {
-		var (
-			extensionDescriptorsList []string
-			extensionFileID          []string
-		)
+		var extensionDescriptorsList []string
 		diags = mtarType.ExtensionDescriptors.ElementsAs(ctx, &extensionDescriptorsList, false)
 		respDiags.Append(diags...)
 
 		for _, descriptorLocation := range extensionDescriptorsList {
-			uploadedExtensionDescriptor, _, err := r.mtaClient.DefaultApi.UploadMtaFile(ctx, spaceGuid, namespace, descriptorLocation)
+			uploadedExtensionDescriptor, _, err := mtaClient.DefaultApi.UploadMtaFile(ctx, spaceGuid, namespace, descriptorLocation)
+			if err != nil {
+				respDiags.AddError(
+					"Unable to upload mta extension descriptor",
+					fmt.Sprintf("Request failed with %s ", err.Error()),
+				)
+				return
+			}
+			extensionFileID = append(extensionFileID, uploadedExtensionDescriptor.Id)
+		}
+	}
+
+	if !mtarType.ExtensionDescriptorContent.IsNull() {
+		var extensionDescriptorContents []string
+		diags = mtarType.ExtensionDescriptorContent.ElementsAs(ctx, &extensionDescriptorContents, false)
+		respDiags.Append(diags...)
+
+		for i, content := range extensionDescriptorContents {
+			uploadedExtensionDescriptor, _, err := mtaClient.DefaultApi.UploadMtaFileContent(ctx, spaceGuid, namespace, fmt.Sprintf("extension-%d.mtaext", i+1), []byte(content))
 			if err != nil {
 				respDiags.AddError(
 					"Unable to upload mta extension descriptor",
@@ -293,11 +764,11 @@ func (r *mtaResource) upsert(ctx context.Context, reqPlan *tfsdk.Plan, reqState
 			}
 			extensionFileID = append(extensionFileID, uploadedExtensionDescriptor.Id)
 		}
-		extensionDescriptors = strings.Join(extensionFileID, ",")
 	}
+	extensionDescriptors = strings.Join(extensionFileID, ",")
 
 	// Check for an ongoing operation for this MTA ID and abort it
-	_, err = mta.CheckOngoingOperation(ctx, r.mtaClient, mtaId, uploadedFile.Namespace, spaceGuid)
+	_, err = mta.CheckOngoingOperation(ctx, mtaClient, mtaId, uploadedFile.Namespace, spaceGuid)
 	if err != nil {
 		respDiags.AddError(
 			"Unable to check for and abort ongoing MTA operation",
@@ -315,21 +786,44 @@ func (r *mtaResource) upsert(ctx context.Context, reqPlan *tfsdk.Plan, reqState
 		},
 	}
 
+	if isBlueGreenStrategy(mtarType.DeployStrategy) {
+		operationParams.ProcessType = "BLUE_GREEN_DEPLOY"
+		operationParams.Parameters["noConfirm"] = !requiresConfirmation(mtarType.BlueGreen)
+		if mtarType.BlueGreen != nil && mtarType.BlueGreen.SkipIdleStart.ValueBool() {
+			operationParams.Parameters["skipIdleStart"] = true
+		}
+		if mtarType.DeployStrategy.ValueString() == mtaIncrementalBlueGreenStrategy {
+			operationParams.Parameters["shouldApplyIncrementalInstancesUpdate"] = true
+		}
+	}
+
 	if extensionDescriptors != "" {
 		operationParams.Parameters["mtaExtDescriptorId"] = extensionDescriptors
 	}
 
+	respDiags.Append(mtarType.addDeployParameters(ctx, operationParams.Parameters)...)
+	if respDiags.HasError() {
+		return
+	}
+
 	//Starting deploy operation
-	operationId, _, _, err := r.mtaClient.DefaultApi.StartMtaOperation(ctx, spaceGuid, operationParams)
+	operationId, _, _, err := mtaClient.DefaultApi.StartMtaOperation(ctx, spaceGuid, operationParams)
 	if err != nil {
 		respDiags.AddError(
-			"Unable to start MTA DEPLOY operation",
+			"Unable to start MTA "+operationParams.ProcessType+" operation",
 			fmt.Sprintf("Request failed with %s ", err.Error()),
 		)
 		return
 	}
 
-	err = mta.PollMtaOperation(ctx, r.mtaClient, spaceGuid, operationId, mta.FinishedState)
+	err = mta.PollMtaOperation(ctx, mtaClient, spaceGuid, operationId, mta.FinishedState)
+	if errors.Is(err, mta.ErrActionRequired) && requiresConfirmation(mtarType.BlueGreen) {
+		err = r.confirmBlueGreenDeploy(ctx, mtaClient, spaceGuid, operationId, mtarType.BlueGreen)
+	}
+	if err != nil {
+		err = r.handleInterruptedOperation(ctx, mtaClient, spaceGuid, operationId, mtarType.AbortOnTimeout, err)
+	}
+	err = r.collectOperationLogs(ctx, mtaClient, spaceGuid, operationId, mtarType.LogsDirectory, err, respDiags)
 	if err != nil {
 		respDiags.AddError(
 			"Failure in polling MTA operation",
@@ -339,7 +833,7 @@ func (r *mtaResource) upsert(ctx context.Context, reqPlan *tfsdk.Plan, reqState
 	}
 
 	//get details of MTA
-	mtaObject, _, err := r.mtaClient.DefaultApi.GetMta(ctx, spaceGuid, mtaId, namespace)
+	mtaObject, _, err := mtaClient.DefaultApi.GetMta(ctx, spaceGuid, mtaId, namespace)
 	if err != nil {
 		respDiags.AddError(
 			"Unable to fetch MTA details",
@@ -355,6 +849,113 @@ func (r *mtaResource) upsert(ctx context.Context, reqPlan *tfsdk.Plan, reqState
 	respDiags.Append(respState.Set(ctx, mtarType)...)
 }
 
+// Aborts an operation whose polling has been interrupted by a timeout or a cancellation, unless it should be left running.
+func (r *mtaResource) handleInterruptedOperation(ctx context.Context, mtaClient *mta.APIClient, spaceGuid string, operationId string, abortOnTimeout types.Bool, err error) error {
+	if !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	if !abortOnTimeout.IsNull() && !abortOnTimeout.ValueBool() {
+		return fmt.Errorf("%s, operation %s has been left running", err.Error(), operationId)
+	}
+
+	abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Minute)
+	defer cancel()
+	if _, _, abortErr := mtaClient.DefaultApi.ExecuteOperationAction(abortCtx, spaceGuid, operationId, "abort"); abortErr != nil {
+		return fmt.Errorf("%s and operation %s could not be aborted: %s", err.Error(), operationId, abortErr.Error())
+	}
+	if abortErr := mta.PollMtaOperation(abortCtx, mtaClient, spaceGuid, operationId, mta.AbortedState); abortErr != nil {
+		return fmt.Errorf("%s and abort of operation %s failed: %s", err.Error(), operationId, abortErr.Error())
+	}
+	return fmt.Errorf("%s, operation %s has been aborted", err.Error(), operationId)
+}
+
+// Writes the logs of an operation to the configured directory and appends the tail of its main log to a failure.
+func (r *mtaResource) collectOperationLogs(ctx context.Context, mtaClient *mta.APIClient, spaceGuid string, operationId string, logsDirectory types.String, err error, diags *diag.Diagnostics) error {
+	logCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+	defer cancel()
+
+	if !logsDirectory.IsNull() {
+		directory, writeErr := mta.WriteMtaOperationLogs(logCtx, mtaClient, spaceGuid, operationId, logsDirectory.ValueString())
+		if writeErr != nil {
+			diags.AddWarning(
+				"Unable to write MTA operation logs",
+				fmt.Sprintf("Writing the logs of operation %s failed with %s", operationId, writeErr.Error()),
+			)
+		} else {
+			tflog.Info(ctx, "wrote MTA operation logs", map[string]interface{}{"directory": directory})
+		}
+	}
+
+	if err == nil {
+		return nil
+	}
+	tail, logErr := mta.GetMtaOperationLogTail(logCtx, mtaClient, spaceGuid, operationId, mtaLogTailLines)
+	if logErr != nil {
+		tflog.Debug(ctx, "unable to read MTA operation log: "+logErr.Error())
+		return err
+	}
+	return fmt.Errorf("%w\nLast lines of the operation log:\n%s", err, tail)
+}
+
+func requiresConfirmation(blueGreen *MtaBlueGreen) bool {
+	return blueGreen != nil && (blueGreen.SmokeCheck != nil || blueGreen.ManualConfirmation.ValueBool())
+}
+
+// Drives a blue-green deployment waiting at its testing phase to completion.
+func (r *mtaResource) confirmBlueGreenDeploy(ctx context.Context, mtaClient *mta.APIClient, spaceGuid string, operationId string, blueGreen *MtaBlueGreen) error {
+	if blueGreen.ManualConfirmation.ValueBool() {
+		tflog.Info(ctx, "waiting for blue-green deployment to be confirmed", map[string]interface{}{"operation_id": operationId, "resume_command": "cf deploy -i " + operationId + " -a resume"})
+		for {
+			if err := sleepWithContext(ctx, 10*time.Second); err != nil {
+				return err
+			}
+			operation, _, err := mtaClient.DefaultApi.GetMtaOperation(ctx, spaceGuid, operationId, "")
+			if err != nil {
+				return err
+			}
+			switch operation.State {
+			case mta.ActionRequiredState:
+				continue
+			case mta.AbortedState:
+				return fmt.Errorf("blue-green deployment was aborted at the testing phase")
+			}
+			return mta.PollMtaOperation(ctx, mtaClient, spaceGuid, operationId, mta.FinishedState)
+		}
+	}
+
+	check := &AppSmokeCheck{
+		ExpectedStatus: blueGreen.SmokeCheck.ExpectedStatus,
+		ExpectedBody:   blueGreen.SmokeCheck.ExpectedBody,
+	}
+	var checkErr error
+	for attempt := 0; attempt < 5; attempt++ {
+		if attempt > 0 {
+			if err := sleepWithContext(ctx, 10*time.Second); err != nil {
+				return err
+			}
+		}
+		if checkErr = runSmokeCheck(ctx, r.cfClient.HTTPClient(), blueGreen.SmokeCheck.Url.ValueString(), check); checkErr == nil {
+			break
+		}
+		tflog.Debug(ctx, "smoke check of idle applications failed: "+checkErr.Error())
+	}
+
+	if checkErr != nil {
+		if _, _, err := mtaClient.DefaultApi.ExecuteOperationAction(ctx, spaceGuid, operationId, "abort"); err != nil {
+			return fmt.Errorf("smoke check failed with %s and the operation could not be aborted: %s", checkErr.Error(), err.Error())
+		}
+		if err := mta.PollMtaOperation(ctx, mtaClient, spaceGuid, operationId, mta.AbortedState); err != nil {
+			return err
+		}
+		return fmt.Errorf("smoke check failed with %s, the blue-green deployment has been aborted", checkErr.Error())
+	}
+
+	if _, _, err := mtaClient.DefaultApi.ExecuteOperationAction(ctx, spaceGuid, operationId, "resume"); err != nil {
+		return err
+	}
+	return mta.PollMtaOperation(ctx, mtaClient, spaceGuid, operationId, mta.FinishedState)
+}
+
 func (r *mtaResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var data MtarType
 	diags := req.State.Get(ctx, &data)
@@ -363,12 +964,17 @@ func (r *mtaResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 		return
 	}
 
-	if !data.DeployUrl.IsNull() {
-		r.mtaClient.ChangeBasePath(data.DeployUrl.ValueString())
+	mtaClient, err := mtaClientForDeployUrl(r.mtaClient, r.mtaClientErr, data.DeployUrl)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to create deploy service client",
+			err.Error(),
+		)
+		return
 	}
 
 	//get details of MTA
-	mtaObject, _, err := r.mtaClient.DefaultApi.GetMta(ctx, data.Space.ValueString(), data.Id.ValueString(), data.Namespace.ValueString())
+	mtaObject, _, err := mtaClient.DefaultApi.GetMta(ctx, data.Space.ValueString(), data.Id.ValueString(), data.Namespace.ValueString())
 	if err != nil {
 		if strings.Contains(err.Error(), mta.MTA_NOT_FOUND) {
 			resp.State.RemoveResource(ctx)
@@ -386,6 +992,8 @@ func (r *mtaResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 	resp.Diagnostics.Append(diags...)
 	data.Mta, diags = types.ObjectValueFrom(ctx, mtaObjAttributes, mtaTfType)
 	resp.Diagnostics.Append(diags...)
+	// The preview describes an applied deployment once it is in the state
+	data.DeploymentPreview = types.ObjectNull(mtaDeploymentPreviewAttributes)
 	tflog.Trace(ctx, "read an mtar resource")
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -398,15 +1006,33 @@ func (r *mtaResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 		return
 	}
 
+	if mtarType.Undeploy != nil && mtarType.Undeploy.KeepOnDestroy.ValueBool() {
+		tflog.Info(ctx, "keeping MTA deployed on destroy", map[string]interface{}{"mta_id": mtarType.Id.ValueString()})
+		return
+	}
+
+	deleteTimeout, diags := mtarType.Timeouts.Delete(ctx, mtaDefaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	mtaId := mtarType.Id.ValueString()
 	spaceGuid := mtarType.Space.ValueString()
 
-	if !mtarType.DeployUrl.IsNull() {
-		r.mtaClient.ChangeBasePath(mtarType.DeployUrl.ValueString())
+	mtaClient, err := mtaClientForDeployUrl(r.mtaClient, r.mtaClientErr, mtarType.DeployUrl)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to create deploy service client",
+			err.Error(),
+		)
+		return
 	}
 
 	// Check for an ongoing operation for this MTA ID and abort it
-	_, err := mta.CheckOngoingOperation(ctx, r.mtaClient, mtaId, mtarType.Namespace.ValueString(), spaceGuid)
+	_, err = mta.CheckOngoingOperation(ctx, mtaClient, mtaId, mtarType.Namespace.ValueString(), spaceGuid)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to check for and abort ongoing MTA operation",
@@ -424,7 +1050,44 @@ func (r *mtaResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 		},
 	}
 
-	operationId, _, _, err := r.mtaClient.DefaultApi.StartMtaOperation(ctx, spaceGuid, operationParams)
+	if undeploy := mtarType.Undeploy; undeploy != nil {
+		if !undeploy.DeleteServices.IsNull() {
+			operationParams.Parameters["deleteServices"] = undeploy.DeleteServices.ValueBool()
+		}
+		if !undeploy.DeleteServiceKeys.IsNull() {
+			operationParams.Parameters["deleteServiceKeys"] = undeploy.DeleteServiceKeys.ValueBool()
+		}
+		if !undeploy.DeleteServiceBrokers.IsNull() {
+			operationParams.Parameters["deleteServiceBrokers"] = undeploy.DeleteServiceBrokers.ValueBool()
+		}
+	}
+
+	// services bound to applications outside of the MTA must not be deleted with it
+	if operationParams.Parameters["deleteServices"] == true {
+		externalBindings, err := r.findExternalServiceBindings(ctx, mtaClient, spaceGuid, mtaId, mtarType.Namespace.ValueString())
+		if err != nil && strings.Contains(err.Error(), mta.MTA_NOT_FOUND) {
+			// the MTA has been undeployed in the meantime, so there is nothing left to undeploy
+			tflog.Info(ctx, "MTA is no longer deployed", map[string]interface{}{"mta_id": mtaId})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to check service bindings of MTA",
+				fmt.Sprintf("Request failed with %s ", err.Error()),
+			)
+			return
+		}
+		if len(externalBindings) > 0 {
+			resp.Diagnostics.AddError(
+				"Services of MTA are bound to other applications",
+				fmt.Sprintf("Refusing to undeploy MTA %s and delete its services as they are still bound to applications outside of the MTA: %s. Unbind them or set undeploy.delete_services to false.", mtaId, strings.Join(externalBindings, ", ")),
+			)
+			return
+		}
+	}
+
+	operationId, _, _, err := mtaClient.DefaultApi.StartMtaOperation(ctx, spaceGuid, operationParams)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to start MTA UNDEPLOY operation",
@@ -433,7 +1096,11 @@ func (r *mtaResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 		return
 	}
 
-	err = mta.PollMtaOperation(ctx, r.mtaClient, spaceGuid, operationId, mta.FinishedState)
+	err = mta.PollMtaOperation(ctx, mtaClient, spaceGuid, operationId, mta.FinishedState)
+	if err != nil {
+		err = r.handleInterruptedOperation(ctx, mtaClient, spaceGuid, operationId, mtarType.AbortOnTimeout, err)
+	}
+	err = r.collectOperationLogs(ctx, mtaClient, spaceGuid, operationId, mtarType.LogsDirectory, err, &resp.Diagnostics)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failure in polling MTA operation",
@@ -443,6 +1110,66 @@ func (r *mtaResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 	}
 }
 
+// Lists the bindings of the services of an MTA to applications which are not part of it.
+func (r *mtaResource) findExternalServiceBindings(ctx context.Context, mtaClient *mta.APIClient, spaceGuid string, mtaId string, namespace string) ([]string, error) {
+	mtaObject, _, err := mtaClient.DefaultApi.GetMta(ctx, spaceGuid, mtaId, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(mtaObject.Services) == 0 {
+		return nil, nil
+	}
+	mtaApps := make(map[string]bool, len(mtaObject.Modules))
+	for _, module := range mtaObject.Modules {
+		mtaApps[module.AppName] = true
+	}
+
+	instances, err := r.cfClient.ServiceInstances.ListAll(ctx, &cfv3client.ServiceInstanceListOptions{
+		ListOptions: cfv3client.NewListOptions(),
+		Names:       cfv3client.Filter{Values: mtaObject.Services},
+		SpaceGUIDs:  cfv3client.Filter{Values: []string{spaceGuid}},
+	})
+	if err != nil || len(instances) == 0 {
+		return nil, err
+	}
+	instanceNames := make(map[string]string, len(instances))
+	instanceGUIDs := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		instanceNames[instance.GUID] = instance.Name
+		instanceGUIDs = append(instanceGUIDs, instance.GUID)
+	}
+
+	bindings, apps, err := r.cfClient.ServiceCredentialBindings.ListIncludeAppsAll(ctx, &cfv3client.ServiceCredentialBindingListOptions{
+		ListOptions:          cfv3client.NewListOptions(),
+		ServiceInstanceGUIDs: cfv3client.Filter{Values: instanceGUIDs},
+		Type:                 cfv3client.Filter{Values: []string{"app"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	appsByGUID := make(map[string]*cfv3resource.App, len(apps))
+	for _, app := range apps {
+		appsByGUID[app.GUID] = app
+	}
+
+	var external []string
+	for _, binding := range bindings {
+		if binding.Relationships.App == nil || binding.Relationships.App.Data == nil || binding.Relationships.ServiceInstance == nil || binding.Relationships.ServiceInstance.Data == nil {
+			continue
+		}
+		app, ok := appsByGUID[binding.Relationships.App.Data.GUID]
+		if !ok {
+			external = append(external, fmt.Sprintf("%s (app %s)", instanceNames[binding.Relationships.ServiceInstance.Data.GUID], binding.Relationships.App.Data.GUID))
+			continue
+		}
+		if app.Relationships.Space.Data != nil && app.Relationships.Space.Data.GUID == spaceGuid && mtaApps[app.Name] {
+			continue
+		}
+		external = append(external, fmt.Sprintf("%s (app %s)", instanceNames[binding.Relationships.ServiceInstance.Data.GUID], app.Name))
+	}
+	return external, nil
+}
+
 func (r *mtaResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	parts := strings.Split(req.ID, "/")
 	if len(parts) > 3 || len(parts) < 2 {
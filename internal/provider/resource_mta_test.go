@@ -1,9 +1,16 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
 	"regexp"
 	"testing"
 
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
@@ -41,6 +48,9 @@ func TestMtaResource_Configure(t *testing.T) {
 						resource.TestCheckResourceAttr(resourceName, "mtar_path", mtarPath),
 						resource.TestCheckResourceAttr(resourceName, "space", spaceGuid),
 						resource.TestCheckResourceAttr(resourceName, "mta.metadata.namespace", namespace),
+						resource.TestCheckResourceAttr(resourceName, "deployment_preview.version", "0.0.0"),
+						resource.TestCheckResourceAttr(resourceName, "deployment_preview.added_modules.0", "my-mta-managed-app-module"),
+						resource.TestCheckNoResourceAttr(resourceName, "deployment_preview.deployed_version"),
 					),
 				},
 				{
@@ -118,6 +128,146 @@ func TestMtaResource_Configure(t *testing.T) {
 		})
 	})
 
+	t.Run("happy path - blue-green deploy confirmed by a smoke check", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_mta_blue_green")
+		defer stopQuietly(rec)
+
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + hclResourceMta(&MtaResourceModelPtr{
+						HclType:        hclObjectResource,
+						HclObjectName:  "rs",
+						MtarPath:       strtostrptr(mtarPath),
+						Space:          strtostrptr(spaceGuid),
+						DeployStrategy: strtostrptr("blue-green"),
+						BlueGreen:      strtostrptr(`{ smoke_check = { url = "https://my-cf-app-idle.cfapps.sap.hana.ondemand.com", expected_status = 200 } }`),
+					}),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "deploy_strategy", "blue-green"),
+						resource.TestCheckResourceAttr(resourceName, "blue_green.smoke_check.expected_status", "200"),
+						resource.TestCheckResourceAttr(resourceName, "mta.modules.0.app_name", "my-cf-app"),
+					),
+				},
+			},
+		})
+	})
+
+	t.Run("error path - destroy mta with services bound to other apps", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_mta_external_bindings")
+		defer stopQuietly(rec)
+		mtaConfig := hclResourceMta(&MtaResourceModelPtr{
+			HclType:       hclObjectResource,
+			HclObjectName: "rs",
+			MtarPath:      strtostrptr(mtarPath2),
+			Space:         strtostrptr(spaceGuid),
+		})
+		// binds my-service of the MTA to an app in tf-space-1 which is not part of it
+		bindingConfig := func(dependsOn string) string {
+			return fmt.Sprintf(`
+		data "cloudfoundry_service_instance" "my_service" {
+			name       = "my-service"
+			space      = "%s"
+			depends_on = [%s]
+		}
+		resource "cloudfoundry_service_credential_binding" "other_app" {
+			type             = "app"
+			app              = "ec6ac2b3-fb79-43c4-9734-000d4299bd59"
+			service_instance = data.cloudfoundry_service_instance.my_service.id
+		}`, spaceGuid, dependsOn)
+		}
+
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + mtaConfig + bindingConfig(resourceName),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "mta.services.0", "my-service"),
+						resource.TestMatchResourceAttr("cloudfoundry_service_credential_binding.other_app", "id", regexpValidUUID),
+					),
+				},
+				{
+					Config:      hclProvider(nil) + bindingConfig(""),
+					ExpectError: regexp.MustCompile(`(?s)Services of MTA are bound to other applications.*my-service`),
+				},
+				{
+					Config: hclProvider(nil) + mtaConfig,
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "mta.services.0", "my-service"),
+					),
+				},
+			},
+		})
+	})
+	t.Run("happy path - destroy mta undeployed in the meantime", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_mta_destroy_undeployed")
+		defer stopQuietly(rec)
+
+		// both resources deploy the same MTA, the second one to be destroyed finds it undeployed
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + hclResourceMta(&MtaResourceModelPtr{
+						HclType:       hclObjectResource,
+						HclObjectName: "rs",
+						MtarPath:      strtostrptr(mtarPath2),
+						Space:         strtostrptr(spaceGuid),
+					}) + hclResourceMta(&MtaResourceModelPtr{
+						HclType:       hclObjectResource,
+						HclObjectName: "rs_copy",
+						MtarPath:      strtostrptr(mtarPath2),
+						Space:         strtostrptr(spaceGuid),
+						DependsOn:     strtostrptr("[" + resourceName + "]"),
+					}),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "id", "my-mta"),
+						resource.TestCheckResourceAttr("cloudfoundry_mta.rs_copy", "id", "my-mta"),
+					),
+				},
+			},
+		})
+	})
+
+	t.Run("happy path - refresh clears the deployment preview", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_mta_refresh")
+		defer stopQuietly(rec)
+
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + hclResourceMta(&MtaResourceModelPtr{
+						HclType:       hclObjectResource,
+						HclObjectName: "rs",
+						MtarPath:      strtostrptr(mtarPath),
+						Space:         strtostrptr(spaceGuid),
+					}),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "deployment_preview.version", "0.0.0"),
+					),
+				},
+				{
+					RefreshState: true,
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckNoResourceAttr(resourceName, "deployment_preview.version"),
+						resource.TestCheckResourceAttr(resourceName, "mta.metadata.version", "0.0.0"),
+					),
+				},
+			},
+		})
+	})
+
 	t.Run("error path - create mtar from invalid path/file", func(t *testing.T) {
 		cfg := getCFHomeConf()
 		rec := cfg.SetupVCR(t, "fixtures/resource_mta_invalid_mta_path")
@@ -182,7 +332,7 @@ func TestMtaResource_Configure(t *testing.T) {
 						MtarPath:             strtostrptr(mtarPath),
 						ExtensionDescriptors: strtostrptr(`["../../assets/provider-config-local.txt"]`),
 					}),
-					ExpectError: regexp.MustCompile(`Failure in polling MTA operation`),
+					ExpectError: regexp.MustCompile(`Invalid MTA Extension Descriptor`),
 				},
 			},
 		})
@@ -230,4 +380,162 @@ func TestMtaResource_Configure(t *testing.T) {
 			},
 		})
 	})
+	t.Run("error path - create mtar with blue-green settings for default strategy", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_mta_invalid_blue_green")
+		defer stopQuietly(rec)
+
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + hclResourceMta(&MtaResourceModelPtr{
+						HclType:       hclObjectResource,
+						HclObjectName: "rs",
+						MtarPath:      strtostrptr(mtarPath),
+						Space:         strtostrptr(spaceGuid),
+						BlueGreen:     strtostrptr(`{ skip_idle_start = true }`),
+					}),
+					ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+				},
+			},
+		})
+	})
+	t.Run("error path - create mtar with extension descriptor content not matching the descriptor", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_mta_invalid_extension_content")
+		defer stopQuietly(rec)
+
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + hclResourceMta(&MtaResourceModelPtr{
+						HclType:                    hclObjectResource,
+						HclObjectName:              "rs",
+						MtarPath:                   strtostrptr(mtarPath2),
+						Space:                      strtostrptr(spaceGuid),
+						ExtensionDescriptorContent: strtostrptr(`["ID: my-mta-dev\nextends: other-mta\nmodules:\n- name: my-app\n"]`),
+					}),
+					ExpectError: regexp.MustCompile(`extends other-mta which is neither the MTA my-mta`),
+				},
+				{
+					Config: hclProvider(nil) + hclResourceMta(&MtaResourceModelPtr{
+						HclType:                    hclObjectResource,
+						HclObjectName:              "rs",
+						MtarPath:                   strtostrptr(mtarPath2),
+						Space:                      strtostrptr(spaceGuid),
+						ExtensionDescriptorContent: strtostrptr(`["ID: my-mta-dev\nextends: my-mta\nmodules:\n- name: my-other-app\n"]`),
+					}),
+					ExpectError: regexp.MustCompile(`refers to module my-other-app which is not part of MTA my-mta`),
+				},
+			},
+		})
+	})
+}
+
+func TestMtaResource_ValidateDeployParameters(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	r := &mtaResource{}
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+	s := schemaResp.Schema
+	stringSet := func(values ...string) tftypes.Value {
+		elements := make([]tftypes.Value, len(values))
+		for i, value := range values {
+			elements[i] = tftypes.NewValue(tftypes.String, value)
+		}
+		return tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, elements)
+	}
+
+	tests := []struct {
+		name       string
+		values     map[string]tftypes.Value
+		wantErrors []string
+		wantParams map[string]interface{}
+	}{
+		{
+			name: "modules and resources of the descriptor",
+			values: map[string]tftypes.Value{
+				"modules":   stringSet("my-app"),
+				"resources": stringSet("my-service"),
+			},
+			wantParams: map[string]interface{}{
+				"modulesForDeployment":   "my-app",
+				"resourcesForDeployment": "my-service",
+			},
+		},
+		{
+			name: "unknown module and resource",
+			values: map[string]tftypes.Value{
+				"modules":   stringSet("my-app", "unknown-module"),
+				"resources": stringSet("unknown-service"),
+			},
+			wantErrors: []string{
+				`module "unknown-module" is not part of MTA my-mta, known names are: my-app`,
+				`resource "unknown-service" is not part of MTA my-mta, known names are: my-service`,
+			},
+		},
+		{
+			name: "other deploy parameters",
+			values: map[string]tftypes.Value{
+				"version_rule":               tftypes.NewValue(tftypes.String, "ALL"),
+				"keep_files":                 tftypes.NewValue(tftypes.Bool, true),
+				"no_restart_subscribed_apps": tftypes.NewValue(tftypes.Bool, false),
+				"delete_service_keys":        tftypes.NewValue(tftypes.Bool, true),
+				"delete_service_brokers":     tftypes.NewValue(tftypes.Bool, false),
+				"abort_on_error":             tftypes.NewValue(tftypes.Bool, true),
+				"additional_parameters": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{
+					"gitUri": tftypes.NewValue(tftypes.String, "https://github.com/SAP/my-mta.git"),
+				}),
+			},
+			wantParams: map[string]interface{}{
+				"versionRule":             "ALL",
+				"keepFiles":               true,
+				"noRestartSubscribedApps": false,
+				"deleteServiceKeys":       true,
+				"deleteServiceBrokers":    false,
+				"abortOnError":            true,
+				"gitUri":                  "https://github.com/SAP/my-mta.git",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := map[string]tftypes.Value{
+				"mtar_path": tftypes.NewValue(tftypes.String, "../../assets/my-mta_1.0.0.mtar"),
+				"space":     tftypes.NewValue(tftypes.String, "02c0cc92-6ecc-44b1-b7b2-096ca19ee143"),
+			}
+			maps.Copy(values, tt.values)
+			config := tfsdk.Config{Schema: s, Raw: newTestValue(t, s, values)}
+			var resp fwresource.ValidateConfigResponse
+			r.ValidateConfig(ctx, fwresource.ValidateConfigRequest{Config: config}, &resp)
+
+			var gotErrors []string
+			for _, d := range resp.Diagnostics.Errors() {
+				gotErrors = append(gotErrors, d.Detail())
+			}
+			if !reflect.DeepEqual(gotErrors, tt.wantErrors) {
+				t.Errorf("ValidateConfig() errors = %v, want %v", gotErrors, tt.wantErrors)
+			}
+			if tt.wantErrors != nil {
+				return
+			}
+
+			var mtarType MtarType
+			if diags := config.Get(ctx, &mtarType); diags.HasError() {
+				t.Fatalf("reading config: %v", diags)
+			}
+			params := map[string]interface{}{}
+			if diags := mtarType.addDeployParameters(ctx, params); diags.HasError() {
+				t.Fatalf("addDeployParameters() diagnostics = %v", diags)
+			}
+			if !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("addDeployParameters() = %v, want %v", params, tt.wantParams)
+			}
+		})
+	}
 }
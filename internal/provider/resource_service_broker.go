@@ -12,6 +12,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 type serviceBrokerResource struct {
@@ -21,6 +22,7 @@ type serviceBrokerResource struct {
 var (
 	_ resource.ResourceWithConfigure   = &serviceBrokerResource{}
 	_ resource.ResourceWithImportState = &serviceBrokerResource{}
+	_ resource.ResourceWithModifyPlan  = &serviceBrokerResource{}
 )
 
 func NewServiceBrokerResource() resource.Resource {
@@ -84,6 +86,14 @@ func (r *serviceBrokerResource) Configure(ctx context.Context, req resource.Conf
 	r.cfClient = session.CFClient
 }
 
+// ModifyPlan picks up unfinished or failed operations of earlier applies.
+func (r *serviceBrokerResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.cfClient == nil {
+		return
+	}
+	planPendingJob(ctx, req.Private, req, resp, path.Root(updatedAtKey), types.StringUnknown())
+}
+
 func (r *serviceBrokerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var plan serviceBrokerType
 	diags := req.Plan.Get(ctx, &plan)
@@ -104,8 +114,14 @@ func (r *serviceBrokerResource) Create(ctx context.Context, req resource.CreateR
 		return
 	}
 
-	err = pollJob(ctx, *r.cfClient, jobID, defaultTimeout)
-	if err != nil {
+	err = awaitJob(ctx, *r.cfClient, resp.Private, pendingJob{GUID: jobID, Operation: jobOperationCreate}, defaultTimeout, &resp.Diagnostics)
+	if isJobTimeout(err) {
+		// The pending job stays in the private state, so the next apply waits for it before the broker is replaced
+		resp.Diagnostics.AddError(
+			"Service broker creation still in progress",
+			"The creation of service broker "+plan.Name.ValueString()+" did not finish within the timeout. The next apply awaits its creation before the broker is replaced.",
+		)
+	} else if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to verify service broker creation",
 			"Service Broker verification failed for "+plan.Name.ValueString()+": "+err.Error(),
@@ -147,6 +163,7 @@ func (r *serviceBrokerResource) Read(ctx context.Context, req resource.ReadReque
 		handleReadErrors(ctx, resp, err, "service_broker", data.ID.ValueString())
 		return
 	}
+	refreshPendingJob(ctx, *r.cfClient, resp.Private, &resp.Diagnostics)
 
 	state, diags := mapServiceBrokerValuesToType(ctx, serviceBroker)
 	resp.Diagnostics.Append(diags...)
@@ -165,6 +182,10 @@ func (r *serviceBrokerResource) Update(ctx context.Context, req resource.UpdateR
 		return
 	}
 
+	if _, err := resumePendingJob(ctx, *r.cfClient, req, resp, defaultTimeout); err != nil {
+		return
+	}
+
 	updateServiceBroker, diags := plan.mapUpdateServiceBrokerTypeToValues(ctx, previousState)
 	resp.Diagnostics.Append(diags...)
 
@@ -178,7 +199,7 @@ func (r *serviceBrokerResource) Update(ctx context.Context, req resource.UpdateR
 	}
 
 	if jobID != "" {
-		if err := pollJob(ctx, *r.cfClient, jobID, defaultTimeout); err != nil {
+		if err := awaitJob(ctx, *r.cfClient, resp.Private, pendingJob{GUID: jobID, Operation: jobOperationUpdate}, defaultTimeout, &resp.Diagnostics); err != nil {
 			resp.Diagnostics.AddError(
 				"Unable to verify service broker update",
 				"Service Broker update verification failed for "+plan.Name.ValueString()+": "+err.Error(),
@@ -210,15 +231,26 @@ func (r *serviceBrokerResource) Delete(ctx context.Context, req resource.DeleteR
 		return
 	}
 
-	jobID, err := r.cfClient.ServiceBrokers.Delete(ctx, state.ID.ValueString())
-	if err != nil {
+	if err := awaitPendingChange(ctx, *r.cfClient, resp.Private, defaultTimeout, &resp.Diagnostics); err != nil {
 		resp.Diagnostics.AddError(
-			"API Error in deleting service broker",
-			"Unable to delete service broker "+state.Name.ValueString()+": "+err.Error(),
+			"Service broker operation still in progress",
+			"Unable to delete service broker "+state.Name.ValueString()+" as its pending operation did not finish: "+err.Error(),
 		)
 		return
 	}
-	if err := pollJob(ctx, *r.cfClient, jobID, defaultTimeout); err != nil {
+	jobID := pendingDeleteJob(ctx, req.Private, &resp.Diagnostics)
+	if jobID == "" {
+		var err error
+		jobID, err = r.cfClient.ServiceBrokers.Delete(ctx, state.ID.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"API Error in deleting service broker",
+				"Unable to delete service broker "+state.Name.ValueString()+": "+err.Error(),
+			)
+			return
+		}
+	}
+	if err := awaitJob(ctx, *r.cfClient, resp.Private, pendingJob{GUID: jobID, Operation: jobOperationDelete}, defaultTimeout, &resp.Diagnostics); err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to verify service broker deletion",
 			"service broker deletion verification failed for "+state.ID.ValueString()+": "+err.Error(),
@@ -16,16 +16,19 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 type serviceCredentialBindingResource struct {
-	cfClient *cfv3client.Client
+	cfClient   *cfv3client.Client
+	deleteMode string
 }
 
 var (
 	_ resource.ResourceWithConfigure      = &serviceCredentialBindingResource{}
 	_ resource.ResourceWithImportState    = &serviceCredentialBindingResource{}
 	_ resource.ResourceWithValidateConfig = &serviceCredentialBindingResource{}
+	_ resource.ResourceWithModifyPlan     = &serviceCredentialBindingResource{}
 )
 
 const (
@@ -81,7 +84,7 @@ func (r *serviceCredentialBindingResource) Schema(ctx context.Context, req resou
 				},
 			},
 			"parameters": schema.StringAttribute{
-				MarkdownDescription: "A JSON object that is passed to the service broker for managed service instance.",
+				MarkdownDescription: "A JSON object that is passed to the service broker for managed service instance. If the service plan provides a schema for the parameters, they are validated against it during planning.",
 				Optional:            true,
 				Sensitive:           true,
 				CustomType:          jsontypes.NormalizedType{},
@@ -89,6 +92,13 @@ func (r *serviceCredentialBindingResource) Schema(ctx context.Context, req resou
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"delete_mode": schema.StringAttribute{
+				MarkdownDescription: "How the service credential binding is destroyed. `normal` deletes it through the service broker and `abandon` only removes it from the Terraform state. As the Cloud Foundry API cannot purge bindings, `purge` deletes it like `normal` but only removes it from the Terraform state with a warning if the service broker fails; Cloud Foundry removes it once its service instance is purged. Defaults to the `default_delete_mode` of the provider or `normal`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(deleteModes...),
+				},
+			},
 			"last_operation": lastOperationSchema(),
 			idKey:            guidSchema(),
 			labelsKey:        resourceLabelsSchema(),
@@ -112,6 +122,7 @@ func (r *serviceCredentialBindingResource) Configure(ctx context.Context, req re
 		return
 	}
 	r.cfClient = session.CFClient
+	r.deleteMode = session.DeleteMode
 }
 
 func (r *serviceCredentialBindingResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
@@ -152,6 +163,59 @@ func (r *serviceCredentialBindingResource) ValidateConfig(ctx context.Context, r
 	}
 }
 
+// ModifyPlan picks up unfinished or failed operations of earlier applies and validates the parameters of a new binding
+// against the binding schema of the service plan of the service instance.
+func (r *serviceCredentialBindingResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.cfClient == nil {
+		return
+	}
+	replaceFailedCreation(ctx, req, resp)
+	planPendingJob(ctx, req.Private, req, resp, path.Root("last_operation"), types.ObjectUnknown(lastOperationAttrTypes))
+	var plan serviceCredentialBindingType
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.Parameters.IsNull() || plan.Parameters.IsUnknown() || plan.ServiceInstance.IsUnknown() {
+		return
+	}
+	if !req.State.Raw.IsNull() {
+		var state serviceCredentialBindingType
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		parametersEqual, diags := state.Parameters.StringSemanticEquals(ctx, plan.Parameters)
+		resp.Diagnostics.Append(diags...)
+		if parametersEqual && state.ServiceInstance.Equal(plan.ServiceInstance) {
+			return
+		}
+	}
+
+	serviceInstance, err := r.cfClient.ServiceInstances.Get(ctx, plan.ServiceInstance.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("service_instance"),
+			"Unable to validate parameters",
+			"Unable to fetch service instance "+plan.ServiceInstance.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+	if serviceInstance.Relationships.ServicePlan == nil || serviceInstance.Relationships.ServicePlan.Data == nil {
+		return
+	}
+	servicePlan, err := r.cfClient.ServicePlans.Get(ctx, serviceInstance.Relationships.ServicePlan.Data.GUID)
+	if err != nil {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("service_instance"),
+			"Unable to validate parameters",
+			"Unable to fetch the schema of service plan "+serviceInstance.Relationships.ServicePlan.Data.GUID+": "+err.Error(),
+		)
+		return
+	}
+	validateServiceParameters(servicePlan.Schemas.ServiceBinding.Create.Parameters, plan.Parameters, path.Root("parameters"), &resp.Diagnostics)
+}
+
 func (r *serviceCredentialBindingResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var (
 		plan                     serviceCredentialBindingType
@@ -179,8 +243,14 @@ func (r *serviceCredentialBindingResource) Create(ctx context.Context, req resou
 		return
 
 	} else if jobID != "" {
-		err = pollJob(ctx, *r.cfClient, jobID, defaultTimeout)
-		if err != nil {
+		err = awaitJob(ctx, *r.cfClient, resp.Private, pendingJob{GUID: jobID, Operation: jobOperationCreate}, defaultTimeout, &resp.Diagnostics)
+		if isJobTimeout(err) {
+			// The pending job stays in the private state, so the next apply waits for it before the binding is replaced
+			resp.Diagnostics.AddError(
+				"Service credential binding creation still in progress",
+				"The creation of service credential binding "+plan.Name.ValueString()+" did not finish within the timeout. The next apply awaits its creation before the binding is replaced.",
+			)
+		} else if err != nil {
 			resp.Diagnostics.AddError(
 				"Unable to verify service credential binding creation",
 				"Service Credential Binding verification failed for "+plan.Name.ValueString()+": "+err.Error(),
@@ -221,6 +291,7 @@ func (r *serviceCredentialBindingResource) Create(ctx context.Context, req resou
 
 	data, diags := mapServiceCredentialBindingValuesToType(ctx, serviceCredentialBinding)
 	data.Parameters = plan.Parameters
+	data.DeleteMode = plan.DeleteMode
 	resp.Diagnostics.Append(diags...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -238,9 +309,11 @@ func (r *serviceCredentialBindingResource) Read(ctx context.Context, req resourc
 		handleReadErrors(ctx, resp, err, "service_credential_binding", data.ID.ValueString())
 		return
 	}
+	refreshPendingJob(ctx, *r.cfClient, resp.Private, &resp.Diagnostics)
 
 	state, diags := mapServiceCredentialBindingValuesToType(ctx, serviceCredentialBinding)
 	state.Parameters = data.Parameters
+	state.DeleteMode = data.DeleteMode
 	resp.Diagnostics.Append(diags...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 
@@ -252,6 +325,10 @@ func (r *serviceCredentialBindingResource) Update(ctx context.Context, req resou
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	resp.Diagnostics.Append(req.State.Get(ctx, &previousState)...)
 
+	if _, err := resumePendingJob(ctx, *r.cfClient, req, resp, defaultTimeout); err != nil {
+		return
+	}
+
 	updateServiceCredentialBinding, diags := plan.mapUpdateServiceCredentialBindingTypeToValues(ctx, previousState)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -269,6 +346,7 @@ func (r *serviceCredentialBindingResource) Update(ctx context.Context, req resou
 
 	data, diags := mapServiceCredentialBindingValuesToType(ctx, serviceCredentialBinding)
 	data.Parameters = plan.Parameters
+	data.DeleteMode = plan.DeleteMode
 	resp.Diagnostics.Append(diags...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -281,23 +359,58 @@ func (r *serviceCredentialBindingResource) Delete(ctx context.Context, req resou
 		return
 	}
 
-	jobID, err := r.cfClient.ServiceCredentialBindings.Delete(ctx, state.ID.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"API Error in deleting service credential binding",
-			"Unable to delete credential binding "+state.Name.ValueString()+": "+err.Error(),
+	mode := resolveDeleteMode(state.DeleteMode, r.deleteMode)
+	if mode == deleteModeAbandon {
+		resp.Diagnostics.AddWarning(
+			"Service credential binding abandoned",
+			"Service credential binding "+state.ID.ValueString()+" has only been removed from the Terraform state and still exists in Cloud Foundry.",
+		)
+		return
+	}
+
+	var deleteDiags diag.Diagnostics
+	if err := awaitPendingChange(ctx, *r.cfClient, resp.Private, defaultTimeout, &deleteDiags); err != nil {
+		deleteDiags.AddError(
+			"Service credential binding operation still in progress",
+			"Unable to delete service credential binding "+state.ID.ValueString()+" as its pending operation did not finish: "+err.Error(),
 		)
+		resp.Diagnostics.Append(deleteDiags...)
+		return
+	}
+	jobID := pendingDeleteJob(ctx, req.Private, &deleteDiags)
+	if jobID == "" {
+		var err error
+		jobID, err = r.cfClient.ServiceCredentialBindings.Delete(ctx, state.ID.ValueString())
+		if err != nil {
+			deleteDiags.AddError(
+				"API Error in deleting service credential binding",
+				"Unable to delete credential binding "+state.Name.ValueString()+": "+err.Error(),
+			)
 
+		}
 	}
 	if jobID != "" {
-		if err := pollJob(ctx, *r.cfClient, jobID, defaultTimeout); err != nil {
-			resp.Diagnostics.AddError(
+		if err := awaitJob(ctx, *r.cfClient, resp.Private, pendingJob{GUID: jobID, Operation: jobOperationDelete}, defaultTimeout, &deleteDiags); err != nil {
+			deleteDiags.AddError(
 				"Unable to verify service credential binding deletion",
 				"service credential binding deletion verification failed for "+state.ID.ValueString()+": "+err.Error(),
 			)
 		}
 	}
 
+	// The API offers no purge for bindings, they are dropped together with their purged service instance.
+	if mode == deleteModePurge && deleteDiags.HasError() {
+		errs := deleteDiags.Errors()
+		resp.Diagnostics.Append(deleteDiags.Warnings()...)
+		resp.Diagnostics.AddWarning(
+			"Service credential binding removed from state",
+			"Service credential binding "+state.ID.ValueString()+" could not be deleted and has been removed from the Terraform state as delete_mode is purge. "+
+				"It is removed from Cloud Foundry once its service instance is purged. "+errs[len(errs)-1].Detail(),
+		)
+		return
+	}
+	resp.Diagnostics.Append(deleteDiags...)
+
 }
 
 func (rs *serviceCredentialBindingResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -7,6 +7,7 @@ import (
 	"text/template"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 type ResourceServiceCredentialBindingModelPtr struct {
@@ -20,6 +21,7 @@ type ResourceServiceCredentialBindingModelPtr struct {
 	App             *string
 	Parameters      *string
 	ServiceInstance *string
+	DeleteMode      *string
 	LastOperation   *string
 	CreatedAt       *string
 	UpdatedAt       *string
@@ -55,6 +57,9 @@ func hclResourceServiceCredentialBinding(sip *ResourceServiceCredentialBindingMo
 				{{.Parameters}}
 				EOT
 			{{- end -}}
+			{{if .DeleteMode}}
+				delete_mode = "{{.DeleteMode}}"
+			{{- end -}}
 			{{if .LastOperation}}
 				last_operation = "{{.LastOperation}}"
 			{{- end -}}
@@ -280,6 +285,67 @@ func TestResourceServiceCredentialBinding(t *testing.T) {
 		})
 	})
 
+	t.Run("happy path - delete service keys according to their delete mode", func(t *testing.T) {
+		var (
+			resourceName = "cloudfoundry_service_credential_binding.sk_abandon"
+			abandonedID  string
+			purged       = hclResourceServiceCredentialBinding(&ResourceServiceCredentialBindingModelPtr{
+				HclType:         hclObjectResource,
+				HclObjectName:   "sk_purge",
+				Name:            strtostrptr("test-sk-purge"),
+				Type:            strtostrptr(keyServiceCredentialBinding),
+				ServiceInstance: strtostrptr(testManagedServiceInstanceGUID),
+				DeleteMode:      strtostrptr(deleteModePurge),
+			})
+			abandoned = &ResourceServiceCredentialBindingModelPtr{
+				HclType:         hclObjectResource,
+				HclObjectName:   "sk_abandon",
+				Name:            strtostrptr("test-sk-abandon"),
+				Type:            strtostrptr(keyServiceCredentialBinding),
+				ServiceInstance: strtostrptr(testManagedServiceInstanceGUID),
+				DeleteMode:      strtostrptr(deleteModeAbandon),
+			}
+		)
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_service_credential_binding_delete_mode")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + purged + hclResourceServiceCredentialBinding(abandoned),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr("cloudfoundry_service_credential_binding.sk_purge", "delete_mode", deleteModePurge),
+						resource.TestCheckResourceAttr(resourceName, "delete_mode", deleteModeAbandon),
+						resource.TestCheckResourceAttrWith(resourceName, "id", func(value string) error {
+							abandonedID = value
+							return nil
+						}),
+					),
+				},
+				{
+					Config: hclProvider(nil),
+				},
+				{
+					// the abandoned service key still exists, import it to have it deleted at the end of the test
+					Config: hclProvider(nil) + hclResourceServiceCredentialBinding(&ResourceServiceCredentialBindingModelPtr{
+						HclType:         abandoned.HclType,
+						HclObjectName:   abandoned.HclObjectName,
+						Name:            abandoned.Name,
+						Type:            abandoned.Type,
+						ServiceInstance: abandoned.ServiceInstance,
+					}),
+					ResourceName: resourceName,
+					ImportState:  true,
+					ImportStateIdFunc: func(*terraform.State) (string, error) {
+						return abandonedID, nil
+					},
+					ImportStatePersist: true,
+				},
+			},
+		})
+	})
 	t.Run("error path - create app binding with existing name", func(t *testing.T) {
 		cfg := getCFHomeConf()
 		rec := cfg.SetupVCR(t, "fixtures/resource_service_credential_binding_invalid_name")
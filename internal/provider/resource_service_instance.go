@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/SAP/terraform-provider-cloudfoundry/internal/provider/managers"
@@ -12,6 +15,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -25,7 +29,8 @@ import (
 )
 
 type serviceInstanceResource struct {
-	cfClient *cfv3client.Client
+	cfClient   *cfv3client.Client
+	deleteMode string
 }
 
 var (
@@ -33,10 +38,14 @@ var (
 	_ resource.ResourceWithConfigure      = &serviceInstanceResource{}
 	_ resource.ResourceWithImportState    = &serviceInstanceResource{}
 	_ resource.ResourceWithValidateConfig = &serviceInstanceResource{}
+	_ resource.ResourceWithModifyPlan     = &serviceInstanceResource{}
 )
 
 const (
 	managedSerivceInstance      = "managed"
+	maintenanceUpgradeNever     = "never"
+	maintenanceUpgradeAuto      = "auto"
+	maintenanceUpgradeVersion   = "version"
 	userProvidedServiceInstance = "user-provided"
 )
 
@@ -78,15 +87,52 @@ https://docs.cloudfoundry.org/devguide/services`,
 				},
 			},
 			"service_plan": schema.StringAttribute{
-				MarkdownDescription: "The ID of the service plan from which to create the service instance",
+				MarkdownDescription: "The ID of the service plan from which to create the service instance. Resolved from `service_offering` and `plan_name` if those are given instead.",
 				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"service_offering": schema.StringAttribute{
+				MarkdownDescription: "The name of the service offering from which to create the service instance; alternative to `service_plan` together with `plan_name`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.AlsoRequires(path.MatchRoot("plan_name")),
+					stringvalidator.ConflictsWith(path.MatchRoot("service_plan")),
+				},
+			},
+			"plan_name": schema.StringAttribute{
+				MarkdownDescription: "The name of the service plan of `service_offering` from which to create the service instance.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.AlsoRequires(path.MatchRoot("service_offering")),
+				},
+			},
+			"service_broker": schema.StringAttribute{
+				MarkdownDescription: "The name of the service broker providing `service_offering`; only needed if several service brokers offer a service with the same name.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.AlsoRequires(path.MatchRoot("service_offering")),
+				},
 			},
 			"parameters": schema.StringAttribute{
-				MarkdownDescription: "A JSON object that is passed to the service broker for managed service instance.",
+				MarkdownDescription: "A JSON object that is passed to the service broker for managed service instance. If the service plan provides a schema for the parameters, they are validated against it during planning.",
 				Optional:            true,
 				Sensitive:           true,
 				CustomType:          jsontypes.NormalizedType{},
 			},
+			"ignore_parameter_keys": schema.SetAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Top-level keys of `parameters` which are not considered when detecting drift, e.g. defaults injected by the service broker. Drift is only detected if the service offering supports retrieving the parameters of its instances.",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
 			"tags": schema.ListAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
@@ -123,6 +169,27 @@ https://docs.cloudfoundry.org/devguide/services`,
 					},
 				},
 			},
+			"maintenance_upgrade": schema.StringAttribute{
+				MarkdownDescription: "Policy for maintenance upgrades of a managed service instance. `never` (default) leaves the version of the service instance alone, `auto` upgrades it whenever the service plan offers a newer version and `version` upgrades it to `maintenance_version`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(maintenanceUpgradeNever, maintenanceUpgradeAuto, maintenanceUpgradeVersion),
+				},
+			},
+			"maintenance_version": schema.StringAttribute{
+				MarkdownDescription: "The maintenance_info version the service instance is upgraded to if `maintenance_upgrade` is `version`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"delete_mode": schema.StringAttribute{
+				MarkdownDescription: "How the service instance is destroyed. `normal` deletes it through the service broker, `purge` removes it and its bindings from Cloud Foundry without contacting the service broker and `abandon` only removes it from the Terraform state. Defaults to the `default_delete_mode` of the provider or `normal`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(deleteModes...),
+				},
+			},
 			"upgrade_available": schema.BoolAttribute{
 				MarkdownDescription: "Whether or not an upgrade of this service instance is available on the current Service Plan; details are available in the maintenance_info object; Only shown when type is managed",
 				Computed:            true,
@@ -162,6 +229,7 @@ func (r *serviceInstanceResource) Configure(ctx context.Context, req resource.Co
 		return
 	}
 	r.cfClient = session.CFClient
+	r.deleteMode = session.DeleteMode
 }
 
 func (r *serviceInstanceResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
@@ -172,7 +240,7 @@ func (r *serviceInstanceResource) ValidateConfig(ctx context.Context, req resour
 		return
 	}
 
-	if config.Type.ValueString() == userProvidedServiceInstance && !config.ServicePlan.IsNull() {
+	if config.Type.ValueString() == userProvidedServiceInstance && (!config.ServicePlan.IsNull() || !config.ServiceOffering.IsNull()) {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("service_plan"),
 			"Conflicting attribute service instance",
@@ -181,11 +249,11 @@ func (r *serviceInstanceResource) ValidateConfig(ctx context.Context, req resour
 		return
 	}
 
-	if config.Type.ValueString() == managedSerivceInstance && config.ServicePlan.IsNull() {
+	if config.Type.ValueString() == managedSerivceInstance && config.ServicePlan.IsNull() && config.ServiceOffering.IsNull() {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("service_plan"),
 			"Missing attribute service instance",
-			"Service plan is required for managed service instance",
+			"Service plan or service offering and plan name are required for managed service instance",
 		)
 		return
 
@@ -201,6 +269,32 @@ func (r *serviceInstanceResource) ValidateConfig(ctx context.Context, req resour
 		return
 	}
 
+	if config.Type.ValueString() == userProvidedServiceInstance && (!config.MaintenanceUpgrade.IsNull() || !config.MaintenanceVersion.IsNull()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("maintenance_upgrade"),
+			"Maintenance upgrades can only be configured for service instances of type managed",
+			"maintenance_upgrade and maintenance_version are not allowed for user-provided service instances",
+		)
+		return
+	}
+	if !config.MaintenanceUpgrade.IsUnknown() && !config.MaintenanceVersion.IsUnknown() {
+		pinned := config.MaintenanceUpgrade.ValueString() == maintenanceUpgradeVersion
+		if pinned && config.MaintenanceVersion.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("maintenance_version"),
+				"Missing attribute maintenance_version",
+				"maintenance_version is required if maintenance_upgrade is version",
+			)
+		}
+		if !pinned && !config.MaintenanceVersion.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("maintenance_version"),
+				"Conflicting attribute maintenance_version",
+				"maintenance_version can only be set if maintenance_upgrade is version",
+			)
+		}
+	}
+
 	// If Service instance of type user-provided then credentials , syslog_drain_url and route_service_url allowed
 	if !config.SyslogDrainURL.IsNull() || !config.RouteServiceURL.IsNull() || !config.Credentials.IsNull() {
 		if config.Type.ValueString() == managedSerivceInstance {
@@ -213,6 +307,199 @@ func (r *serviceInstanceResource) ValidateConfig(ctx context.Context, req resour
 	}
 }
 
+// ModifyPlan picks up unfinished or failed operations of earlier applies and validates the parameters of a managed
+// service instance against the schema of its service plan, so that invalid parameters are reported before the broker
+// starts to provision or update the instance.
+func (r *serviceInstanceResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.cfClient == nil {
+		return
+	}
+	replaceFailedCreation(ctx, req, resp)
+	planPendingJob(ctx, req.Private, req, resp, path.Root("last_operation"), types.ObjectUnknown(lastOperationAttrTypes))
+	r.planServicePlanByName(ctx, resp)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var plan serviceInstanceType
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	creating := req.State.Raw.IsNull()
+	var state serviceInstanceType
+	if !creating {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		r.planMaintenanceUpgrade(ctx, plan, state, resp)
+	}
+	if plan.Type.ValueString() != managedSerivceInstance || plan.Parameters.IsNull() || plan.Parameters.IsUnknown() ||
+		plan.ServicePlan.IsNull() || plan.ServicePlan.IsUnknown() {
+		return
+	}
+
+	if !creating {
+		parametersEqual, diags := state.Parameters.StringSemanticEquals(ctx, plan.Parameters)
+		resp.Diagnostics.Append(diags...)
+		if parametersEqual && state.ServicePlan.Equal(plan.ServicePlan) {
+			return
+		}
+	}
+
+	servicePlan, err := r.cfClient.ServicePlans.Get(ctx, plan.ServicePlan.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("service_plan"),
+			"Unable to validate parameters",
+			"Unable to fetch the schema of service plan "+plan.ServicePlan.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+	parametersSchema := servicePlan.Schemas.ServiceInstance.Update.Parameters
+	if creating {
+		parametersSchema = servicePlan.Schemas.ServiceInstance.Create.Parameters
+	}
+	validateServiceParameters(parametersSchema, plan.Parameters, path.Root("parameters"), &resp.Diagnostics)
+}
+
+// planServicePlanByName resolves service_offering and plan_name to the GUID of a service plan which is visible in the
+// space of the service instance and sets it as service_plan of the plan.
+func (r *serviceInstanceResource) planServicePlanByName(ctx context.Context, resp *resource.ModifyPlanResponse) {
+	var plan serviceInstanceType
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.Type.ValueString() == userProvidedServiceInstance && plan.ServicePlan.IsUnknown() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("service_plan"), types.StringNull())...)
+		return
+	}
+	if plan.ServiceOffering.IsNull() {
+		return
+	}
+	if plan.ServiceOffering.IsUnknown() || plan.PlanName.IsUnknown() || plan.ServiceBroker.IsUnknown() || plan.Space.IsUnknown() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("service_plan"), types.StringUnknown())...)
+		return
+	}
+
+	opts := cfv3client.NewServicePlanListOptions()
+	opts.Names = cfv3client.Filter{Values: []string{plan.PlanName.ValueString()}}
+	opts.ServiceOfferingNames = cfv3client.Filter{Values: []string{plan.ServiceOffering.ValueString()}}
+	opts.SpaceGUIDs = cfv3client.Filter{Values: []string{plan.Space.ValueString()}}
+	if !plan.ServiceBroker.IsNull() {
+		opts.ServiceBrokerNames = cfv3client.Filter{Values: []string{plan.ServiceBroker.ValueString()}}
+	}
+	servicePlans, serviceOfferings, err := r.cfClient.ServicePlans.ListIncludeServiceOfferingAll(ctx, opts)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"API Error in resolving service plan",
+			"Unable to fetch service plan "+plan.PlanName.ValueString()+" of service offering "+plan.ServiceOffering.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+
+	switch len(servicePlans) {
+	case 0:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("plan_name"),
+			"Service plan not found",
+			fmt.Sprintf("No service plan %s of service offering %s is visible in space %s.", plan.PlanName.ValueString(), plan.ServiceOffering.ValueString(), plan.Space.ValueString()),
+		)
+	case 1:
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("service_plan"), types.StringValue(servicePlans[0].GUID))...)
+	default:
+		brokerGUIDs := make([]string, 0, len(serviceOfferings))
+		for _, serviceOffering := range serviceOfferings {
+			if serviceOffering.Relationships.ServiceBroker.Data != nil {
+				brokerGUIDs = append(brokerGUIDs, serviceOffering.Relationships.ServiceBroker.Data.GUID)
+			}
+		}
+		brokers := r.serviceBrokerNames(ctx, brokerGUIDs)
+		resp.Diagnostics.AddAttributeError(
+			path.Root("service_offering"),
+			"Ambiguous service plan",
+			fmt.Sprintf("Service plan %s of service offering %s is offered by several service brokers (%s). Please set service_broker to the name of one of them.",
+				plan.PlanName.ValueString(), plan.ServiceOffering.ValueString(), strings.Join(brokers, ", ")),
+		)
+	}
+}
+
+// serviceBrokerNames returns the sorted names of the service brokers with the given GUIDs. The GUID is kept for service
+// brokers which cannot be read, as they are only visible to admins and to members of the space they are scoped to.
+func (r *serviceInstanceResource) serviceBrokerNames(ctx context.Context, guids []string) []string {
+	// the API does not filter service brokers by GUID
+	serviceBrokers, err := r.cfClient.ServiceBrokers.ListAll(ctx, cfv3client.NewServiceBrokerListOptions())
+	if err != nil {
+		tflog.Debug(ctx, "unable to list service brokers: "+err.Error())
+	}
+	brokerNames := make(map[string]string, len(serviceBrokers))
+	for _, serviceBroker := range serviceBrokers {
+		brokerNames[serviceBroker.GUID] = serviceBroker.Name
+	}
+	names := make([]string, 0, len(guids))
+	for _, guid := range guids {
+		if name, ok := brokerNames[guid]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, guid)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// maintenanceUpgradeTarget returns the maintenance_info version and description the managed service instance is to be
+// upgraded to according to its maintenance_upgrade policy, or an empty version if no upgrade is due. Changes of the
+// service plan are left out, as they move the service instance to the version of the new plan anyway.
+func (r *serviceInstanceResource) maintenanceUpgradeTarget(ctx context.Context, plan, state serviceInstanceType) (string, string, diag.Diagnostics) {
+	if plan.Type.ValueString() != managedSerivceInstance || plan.MaintenanceUpgrade.IsUnknown() || plan.MaintenanceVersion.IsUnknown() ||
+		!plan.ServicePlan.Equal(state.ServicePlan) {
+		return "", "", nil
+	}
+	current, diags := state.maintenanceInfoVersion(ctx)
+	switch plan.MaintenanceUpgrade.ValueString() {
+	case maintenanceUpgradeVersion:
+		if plan.MaintenanceVersion.ValueString() == current {
+			return "", "", diags
+		}
+		return plan.MaintenanceVersion.ValueString(), "", diags
+	case maintenanceUpgradeAuto:
+		if !state.UpgradeAvailable.ValueBool() {
+			return "", "", diags
+		}
+		servicePlan, err := r.cfClient.ServicePlans.Get(ctx, state.ServicePlan.ValueString())
+		if err != nil {
+			diags.AddError(
+				"Unable to determine maintenance upgrade",
+				"Unable to fetch service plan "+state.ServicePlan.ValueString()+": "+err.Error(),
+			)
+			return "", "", diags
+		}
+		if servicePlan.MaintenanceInfo.Version == current {
+			return "", "", diags
+		}
+		return servicePlan.MaintenanceInfo.Version, servicePlan.MaintenanceInfo.Description, diags
+	}
+	return "", "", diags
+}
+
+// planMaintenanceUpgrade marks the maintenance information as unknown if a maintenance upgrade is due, so that the
+// upgrade is carried out by the next apply.
+func (r *serviceInstanceResource) planMaintenanceUpgrade(ctx context.Context, plan, state serviceInstanceType, resp *resource.ModifyPlanResponse) {
+	version, _, diags := r.maintenanceUpgradeTarget(ctx, plan, state)
+	resp.Diagnostics.Append(diags...)
+	if version == "" {
+		return
+	}
+	tflog.Info(ctx, "planning maintenance upgrade of service instance", map[string]interface{}{
+		"service_instance": state.ID.ValueString(),
+		"version":          version,
+	})
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("maintenance_info"), types.ObjectUnknown(maintenanceInfoAttrTypes))...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("upgrade_available"), types.BoolUnknown())...)
+}
+
 func (r *serviceInstanceResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var plan, state serviceInstanceType
 	var serviceInstance *cfv3resource.ServiceInstance
@@ -285,11 +572,19 @@ func (r *serviceInstanceResource) Create(ctx context.Context, req resource.Creat
 			)
 			return
 		}
-		if err = pollJob(ctx, *r.cfClient, jobID, createTimeout); err != nil {
-			resp.Diagnostics.AddError(
-				"Unable to verify service instance creation",
-				"Service Instance verification failed for+ "+plan.Name.ValueString()+": "+err.Error(),
-			)
+		if err = awaitJob(ctx, *r.cfClient, resp.Private, pendingJob{GUID: jobID, Operation: jobOperationCreate}, createTimeout, &resp.Diagnostics); err != nil {
+			if isJobTimeout(err) {
+				// The pending job stays in the private state, so the next apply waits for it before the instance is replaced
+				resp.Diagnostics.AddError(
+					"Service instance creation still in progress",
+					"The creation of service instance "+plan.Name.ValueString()+" did not finish within the create timeout. The next apply awaits its creation before the instance is replaced.",
+				)
+			} else {
+				resp.Diagnostics.AddError(
+					"Unable to verify service instance creation",
+					"Service Instance verification failed for+ "+plan.Name.ValueString()+": "+err.Error(),
+				)
+			}
 		}
 		serviceInstance, err = r.cfClient.ServiceInstances.Single(ctx, &cfv3client.ServiceInstanceListOptions{
 			Names: cfv3client.Filter{
@@ -391,7 +686,7 @@ func (r *serviceInstanceResource) Create(ctx context.Context, req resource.Creat
 		resp.Diagnostics.Append(diags...)
 
 	}
-	state.Timeouts = plan.Timeouts
+	state.copyConfigFrom(plan)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 
 }
@@ -409,19 +704,60 @@ func (r *serviceInstanceResource) Read(ctx context.Context, req resource.ReadReq
 		handleReadErrors(ctx, resp, err, "service_instance", data.ID.ValueString())
 		return
 	}
+	refreshPendingJob(ctx, *r.cfClient, resp.Private, &resp.Diagnostics)
 
 	switch svcInstance.Type {
 	case managedSerivceInstance:
 		newState, diags = mapResourceServiceInstanceValuesToType(ctx, svcInstance, data.Parameters)
+		resp.Diagnostics.Append(diags...)
+		newState.Parameters, diags = r.readManagedParameters(ctx, svcInstance, data)
 	case userProvidedServiceInstance:
 		newState, diags = mapResourceServiceInstanceValuesToType(ctx, svcInstance, data.Credentials)
 	}
-	newState.Timeouts = data.Timeouts
+	newState.copyConfigFrom(data)
 	resp.Diagnostics.Append(diags...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &newState)...)
 
 }
 
+// readManagedParameters returns the parameters of a managed service instance as known to the service broker, so that
+// changes made outside of Terraform show up as drift. The parameters from the state are returned unchanged if they are
+// not managed by Terraform or if the service offering does not support retrieving them.
+func (r *serviceInstanceResource) readManagedParameters(ctx context.Context, svcInstance *cfv3resource.ServiceInstance, data serviceInstanceType) (jsontypes.Normalized, diag.Diagnostics) {
+	if data.Parameters.IsNull() || data.Parameters.IsUnknown() ||
+		svcInstance.Relationships.ServicePlan == nil || svcInstance.Relationships.ServicePlan.Data == nil {
+		return data.Parameters, nil
+	}
+	_, serviceOffering, err := r.cfClient.ServicePlans.GetIncludeServicePlan(ctx, svcInstance.Relationships.ServicePlan.Data.GUID)
+	if err != nil {
+		tflog.Warn(ctx, "unable to fetch service offering, skipping drift detection of parameters", map[string]interface{}{
+			"service_instance": svcInstance.GUID,
+			"error":            err.Error(),
+		})
+		return data.Parameters, nil
+	}
+	if !serviceOffering.BrokerCatalog.Features.InstancesRetrievable {
+		return data.Parameters, nil
+	}
+	parameters, err := r.cfClient.ServiceInstances.GetManagedParameters(ctx, svcInstance.GUID)
+	if err != nil || parameters == nil {
+		tflog.Warn(ctx, "unable to retrieve parameters, skipping drift detection of parameters", map[string]interface{}{
+			"service_instance": svcInstance.GUID,
+			"error":            fmt.Sprint(err),
+		})
+		return data.Parameters, nil
+	}
+
+	var diags diag.Diagnostics
+	ignoreKeys := []string{}
+	if !data.IgnoreParameterKeys.IsNull() {
+		diags.Append(data.IgnoreParameterKeys.ElementsAs(ctx, &ignoreKeys, false)...)
+	}
+	merged, mergeDiags := mergeManagedParameters(ctx, data.Parameters, *parameters, ignoreKeys)
+	diags.Append(mergeDiags...)
+	return merged, diags
+}
+
 func (r *serviceInstanceResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 
 	var plan, state, previousState serviceInstanceType
@@ -437,77 +773,114 @@ func (r *serviceInstanceResource) Update(ctx context.Context, req resource.Updat
 			"detail":  errors[0].Detail(),
 		})
 	}
+	// An update which failed before is retried even if the configuration did not change since.
+	pending, diags := getPendingJob(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	retry := pending != nil && pending.Failed && pending.Operation == jobOperationUpdate
+	if _, err := resumePendingJob(ctx, *r.cfClient, req, resp, updateTimeout); err != nil {
+		return
+	}
 	switch plan.Type.ValueString() {
 	case managedSerivceInstance:
 
-		updateServiceInstance := cfv3resource.ServiceInstanceManagedUpdate{
-			Name: plan.Name.ValueStringPointer(),
+		version, description, diags := r.maintenanceUpgradeTarget(ctx, plan, previousState)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
-		// Check if the service plan is different from the previous state
-		if plan.ServicePlan.ValueString() != previousState.ServicePlan.ValueString() {
-			ok, err := isServiceInstanceUpgradable(ctx, previousState.ID.ValueString(), *r.cfClient)
+		if version != "" {
+			maintenanceUpdate := cfv3resource.ServiceInstanceManagedUpdate{}
+			jobID, _, err := r.cfClient.ServiceInstances.UpdateManaged(ctx, previousState.ID.ValueString(), maintenanceUpdate.WithMaintenanceInfo(version, description))
 			if err != nil {
 				resp.Diagnostics.AddError(
-					"Error in checking service instance upgradability",
-					"Unable to check service instance upgradability"+plan.Name.ValueString()+": "+err.Error(),
+					"API Error in upgrading managed service instance",
+					"Unable to upgrade service instance "+plan.Name.ValueString()+" to version "+version+": "+err.Error(),
 				)
+				resp.State.Raw = req.State.Raw
 				return
 			}
-			if !ok {
-				resp.Diagnostics.AddError(
-					"Service instance not upgradable",
-					"Service instance "+plan.Name.ValueString()+" is not upgradable",
-				)
-				return
+			if jobID != "" {
+				if err := awaitJob(ctx, *r.cfClient, resp.Private, pendingJob{GUID: jobID, Operation: jobOperationUpdate}, updateTimeout, &resp.Diagnostics); err != nil {
+					resp.Diagnostics.AddError(
+						"Unable to verify service instance upgrade",
+						"Service Instance upgrade verification failed for "+plan.Name.ValueString()+": "+err.Error(),
+					)
+					resp.State.Raw = req.State.Raw
+					return
+				}
 			}
-			updateServiceInstance.Relationships = &cfv3resource.ServiceInstanceRelationships{
-				ServicePlan: &cfv3resource.ToOneRelationship{
-					Data: &cfv3resource.Relationship{
-						GUID: plan.ServicePlan.ValueString(),
+		}
+
+		if retry || managedServiceInstanceChanged(plan, previousState) {
+			updateServiceInstance := cfv3resource.ServiceInstanceManagedUpdate{
+				Name: plan.Name.ValueStringPointer(),
+			}
+			// Check if the service plan is different from the previous state
+			if plan.ServicePlan.ValueString() != previousState.ServicePlan.ValueString() {
+				ok, err := isServiceInstanceUpgradable(ctx, previousState.ID.ValueString(), *r.cfClient)
+				if err != nil {
+					resp.Diagnostics.AddError(
+						"Error in checking service instance upgradability",
+						"Unable to check service instance upgradability"+plan.Name.ValueString()+": "+err.Error(),
+					)
+					return
+				}
+				if !ok {
+					resp.Diagnostics.AddError(
+						"Service instance not upgradable",
+						"Service instance "+plan.Name.ValueString()+" is not upgradable",
+					)
+					return
+				}
+				updateServiceInstance.Relationships = &cfv3resource.ServiceInstanceRelationships{
+					ServicePlan: &cfv3resource.ToOneRelationship{
+						Data: &cfv3resource.Relationship{
+							GUID: plan.ServicePlan.ValueString(),
+						},
 					},
-				},
+				}
 			}
-		}
-		if !plan.Parameters.IsNull() {
-			var params json.RawMessage
-			err := json.Unmarshal([]byte(plan.Parameters.ValueString()), &params)
-			if err != nil {
-				resp.Diagnostics.AddError(
-					"Error in unmarshalling parameters",
-					"Unable to unmarshal json parameters during update of service instance"+plan.Name.ValueString()+": "+err.Error(),
-				)
-				return
+			if !plan.Parameters.IsNull() {
+				var params json.RawMessage
+				err := json.Unmarshal([]byte(plan.Parameters.ValueString()), &params)
+				if err != nil {
+					resp.Diagnostics.AddError(
+						"Error in unmarshalling parameters",
+						"Unable to unmarshal json parameters during update of service instance"+plan.Name.ValueString()+": "+err.Error(),
+					)
+					return
+				}
+				updateServiceInstance.Parameters = &params
 			}
-			updateServiceInstance.Parameters = &params
-		}
 
-		tags, diags := toTagsList(ctx, plan.Tags)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		updateServiceInstance.Tags = tags
+			tags, diags := toTagsList(ctx, plan.Tags)
+			resp.Diagnostics.Append(diags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			updateServiceInstance.Tags = tags
 
-		updateServiceInstance.Metadata, diags = setClientMetadataForUpdate(ctx, previousState.Labels, previousState.Annotations, plan.Labels, plan.Annotations)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
+			updateServiceInstance.Metadata, diags = setClientMetadataForUpdate(ctx, previousState.Labels, previousState.Annotations, plan.Labels, plan.Annotations)
+			resp.Diagnostics.Append(diags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
 
-		jobID, _, err := r.cfClient.ServiceInstances.UpdateManaged(ctx, previousState.ID.ValueString(), &updateServiceInstance)
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"API Error in updating managed service instance",
-				"Unable to update service instance "+plan.Name.ValueString()+": "+err.Error(),
-			)
-		}
-		if jobID != "" {
-			if err := pollJob(ctx, *r.cfClient, jobID, updateTimeout); err != nil {
+			jobID, _, err := r.cfClient.ServiceInstances.UpdateManaged(ctx, previousState.ID.ValueString(), &updateServiceInstance)
+			if err != nil {
 				resp.Diagnostics.AddError(
-					"Unable to verify service instance update",
-					"Service Instance update verification failed for "+plan.Name.ValueString()+": "+err.Error(),
+					"API Error in updating managed service instance",
+					"Unable to update service instance "+plan.Name.ValueString()+": "+err.Error(),
 				)
 			}
+			if jobID != "" {
+				if err := awaitJob(ctx, *r.cfClient, resp.Private, pendingJob{GUID: jobID, Operation: jobOperationUpdate}, updateTimeout, &resp.Diagnostics); err != nil {
+					resp.Diagnostics.AddError(
+						"Unable to verify service instance update",
+						"Service Instance update verification failed for "+plan.Name.ValueString()+": "+err.Error(),
+					)
+				}
+			}
 		}
 		serviceInstance, err := r.cfClient.ServiceInstances.Get(ctx, plan.ID.ValueString())
 		if err != nil {
@@ -565,7 +938,7 @@ func (r *serviceInstanceResource) Update(ctx context.Context, req resource.Updat
 		state, diags = mapResourceServiceInstanceValuesToType(ctx, serviceInstance, plan.Credentials)
 		resp.Diagnostics.Append(diags...)
 	}
-	state.Timeouts = plan.Timeouts
+	state.copyConfigFrom(plan)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 
 }
@@ -586,16 +959,49 @@ func (r *serviceInstanceResource) Delete(ctx context.Context, req resource.Delet
 		})
 	}
 
-	jobID, err := r.cfClient.ServiceInstances.Delete(ctx, state.ID.ValueString())
-	if err != nil {
+	switch resolveDeleteMode(state.DeleteMode, r.deleteMode) {
+	case deleteModeAbandon:
+		resp.Diagnostics.AddWarning(
+			"Service instance abandoned",
+			"Service instance "+state.Name.ValueString()+" has only been removed from the Terraform state and still exists in Cloud Foundry.",
+		)
+		return
+	case deleteModePurge:
+		err := cfRawRequest(ctx, r.cfClient, http.MethodDelete, "/v3/service_instances/"+state.ID.ValueString()+"?purge=true", nil, nil)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"API Error in purging service instance",
+				"Unable to purge service instance "+state.Name.ValueString()+": "+err.Error(),
+			)
+			return
+		}
+		tflog.Info(ctx, "purged service instance", map[string]interface{}{
+			"id": state.ID.ValueString(),
+		})
+		return
+	}
+
+	if err := awaitPendingChange(ctx, *r.cfClient, resp.Private, deleteTimeout, &resp.Diagnostics); err != nil {
 		resp.Diagnostics.AddError(
-			"API Error in deleting service instance",
-			"Unable to delete service instance "+state.Name.ValueString()+": "+err.Error(),
+			"Service instance operation still in progress",
+			"Unable to delete service instance "+state.Name.ValueString()+" as its pending operation did not finish: "+err.Error(),
 		)
+		return
+	}
+	jobID := pendingDeleteJob(ctx, req.Private, &resp.Diagnostics)
+	if jobID == "" {
+		var err error
+		jobID, err = r.cfClient.ServiceInstances.Delete(ctx, state.ID.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"API Error in deleting service instance",
+				"Unable to delete service instance "+state.Name.ValueString()+": "+err.Error(),
+			)
 
+		}
 	}
 	if jobID != "" {
-		if err := pollJob(ctx, *r.cfClient, jobID, deleteTimeout); err != nil {
+		if err := awaitJob(ctx, *r.cfClient, resp.Private, pendingJob{GUID: jobID, Operation: jobOperationDelete}, deleteTimeout, &resp.Diagnostics); err != nil {
 			resp.Diagnostics.AddError(
 				"Unable to verify service instance deletion",
 				"Service Instance deletion verification failed for "+state.ID.ValueString()+": "+err.Error(),
@@ -1,10 +1,13 @@
 package provider
 
 import (
+	"context"
 	"regexp"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 func TestResourceServiceInstance(t *testing.T) {
@@ -252,5 +255,377 @@ func TestResourceServiceInstance(t *testing.T) {
 			},
 		})
 	})
+	t.Run("error path - maintenance version without version upgrade policy", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_service_instance_invalid_maintenance_version")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + hclServiceInstance(&ServiceInstanceModelPtr{
+						HclType:            hclObjectResource,
+						HclObjectName:      "si_invalid_maintenance_version",
+						Name:               strtostrptr("test-si-invalid-maintenance-version"),
+						Type:               strtostrptr(managedSerivceInstance),
+						Space:              strtostrptr(testSpaceGUID),
+						ServicePlan:        strtostrptr(testServicePanGUID),
+						MaintenanceUpgrade: strtostrptr(maintenanceUpgradeAuto),
+						MaintenanceVersion: strtostrptr("2.0.0"),
+					}),
+					ExpectError: regexp.MustCompile(`Conflicting attribute maintenance_version`),
+				},
+			},
+		})
+	})
+	t.Run("error path - service offering without plan name", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_service_instance_invalid_service_offering")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + hclServiceInstance(&ServiceInstanceModelPtr{
+						HclType:         hclObjectResource,
+						HclObjectName:   "si_invalid_service_offering",
+						Name:            strtostrptr("test-si-invalid-service-offering"),
+						Type:            strtostrptr(managedSerivceInstance),
+						Space:           strtostrptr(testSpaceGUID),
+						ServiceOffering: strtostrptr("xsuaa"),
+					}),
+					ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+				},
+			},
+		})
+	})
+	t.Run("happy path - read back the parameters of a managed service instance", func(t *testing.T) {
+		resourceName := "cloudfoundry_service_instance.si_parameters"
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_service_instance_parameters")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + hclServiceInstance(&ServiceInstanceModelPtr{
+						HclType:         hclObjectResource,
+						HclObjectName:   "si_parameters",
+						Name:            strtostrptr("test-si-parameters"),
+						Type:            strtostrptr(managedSerivceInstance),
+						Space:           strtostrptr(testSpaceGUID),
+						ServicePlan:     strtostrptr(testServicePanGUID),
+						Parameters:      strtostrptr(testParameters),
+						IgnoreParamKeys: strtostrptr(`["oauth2-configuration"]`),
+					}),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestMatchResourceAttr(resourceName, "parameters", regexp.MustCompile(`"tf test1"`)),
+						resource.TestCheckResourceAttr(resourceName, "ignore_parameter_keys.0", "oauth2-configuration"),
+					),
+				},
+				{
+					RefreshState: true,
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestMatchResourceAttr(resourceName, "parameters", regexp.MustCompile(`"tf test1"`)),
+					),
+				},
+			},
+		})
+	})
+	t.Run("happy path - maintenance upgrade policy of a managed service instance", func(t *testing.T) {
+		resourceName := "cloudfoundry_service_instance.si_maintenance"
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_service_instance_maintenance_upgrade")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + hclServiceInstance(&ServiceInstanceModelPtr{
+						HclType:            hclObjectResource,
+						HclObjectName:      "si_maintenance",
+						Name:               strtostrptr("test-si-maintenance"),
+						Type:               strtostrptr(managedSerivceInstance),
+						Space:              strtostrptr(testSpaceGUID),
+						ServicePlan:        strtostrptr(testServicePanGUID),
+						Parameters:         strtostrptr(testParameters),
+						MaintenanceUpgrade: strtostrptr(maintenanceUpgradeAuto),
+					}),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "maintenance_upgrade", maintenanceUpgradeAuto),
+						resource.TestCheckResourceAttrSet(resourceName, "maintenance_info.version"),
+						resource.TestCheckResourceAttr(resourceName, "upgrade_available", "false"),
+					),
+				},
+				{
+					Config: hclProvider(nil) + hclServiceInstance(&ServiceInstanceModelPtr{
+						HclType:            hclObjectResource,
+						HclObjectName:      "si_maintenance",
+						Name:               strtostrptr("test-si-maintenance"),
+						Type:               strtostrptr(managedSerivceInstance),
+						Space:              strtostrptr(testSpaceGUID),
+						ServicePlan:        strtostrptr(testServicePanGUID),
+						Parameters:         strtostrptr(testParameters),
+						MaintenanceUpgrade: strtostrptr(maintenanceUpgradeNever),
+					}),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "maintenance_upgrade", maintenanceUpgradeNever),
+						resource.TestCheckResourceAttrSet(resourceName, "maintenance_info.version"),
+					),
+				},
+			},
+		})
+	})
+	t.Run("happy path - create service instance by service offering and plan name", func(t *testing.T) {
+		resourceName := "cloudfoundry_service_instance.si_plan_name"
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_service_instance_plan_name")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + hclServiceInstance(&ServiceInstanceModelPtr{
+						HclType:         hclObjectResource,
+						HclObjectName:   "si_plan_name",
+						Name:            strtostrptr("test-si-plan-name"),
+						Type:            strtostrptr(managedSerivceInstance),
+						Space:           strtostrptr(testSpaceGUID),
+						ServiceOffering: strtostrptr("xsuaa"),
+						PlanName:        strtostrptr("application"),
+						ServiceBroker:   strtostrptr("xsuaa"),
+						Parameters:      strtostrptr(testParameters),
+					}),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "service_plan", testServicePanGUID),
+						resource.TestCheckResourceAttr(resourceName, "service_offering", "xsuaa"),
+						resource.TestCheckResourceAttr(resourceName, "plan_name", "application"),
+					),
+				},
+			},
+		})
+	})
+	t.Run("error path - service plan offered by several service brokers", func(t *testing.T) {
+		var (
+			// the sample broker is registered globally as "hi", registering it in the space as well
+			// makes each of its service plans ambiguous
+			brokerURL       = "https://sample-broker.cert.cfapps.stagingazure.hanavlab.ondemand.com"
+			brokerUsername  = "admin"
+			brokerPassword  = "hi"
+			spaceBrokerName = "space-broker"
+			serviceBroker   = hclProvider(nil) + hclServiceBroker(&ServiceBrokerModelPtr{
+				HclType:       hclObjectResource,
+				HclObjectName: "space_broker",
+				Name:          &spaceBrokerName,
+				Url:           &brokerURL,
+				Username:      &brokerUsername,
+				Password:      &brokerPassword,
+				Space:         strtostrptr(testSpaceGUID),
+			})
+		)
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_service_instance_ambiguous_plan")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: serviceBroker,
+				},
+				{
+					Config: serviceBroker + hclServiceInstance(&ServiceInstanceModelPtr{
+						HclType:         hclObjectResource,
+						HclObjectName:   "si_ambiguous_plan",
+						Name:            strtostrptr("test-si-ambiguous-plan"),
+						Type:            strtostrptr(managedSerivceInstance),
+						Space:           strtostrptr(testSpaceGUID),
+						ServiceOffering: strtostrptr("sample-service"),
+						PlanName:        strtostrptr("sample-plan"),
+					}),
+					ExpectError: regexp.MustCompile(`(?s)Ambiguous service plan.*hi, space-broker`),
+				},
+			},
+		})
+	})
+	t.Run("happy path - delete service instances according to their delete mode", func(t *testing.T) {
+		var (
+			resourceName = "cloudfoundry_service_instance.si_abandon"
+			abandonedID  string
+			purged       = hclServiceInstance(&ServiceInstanceModelPtr{
+				HclType:       hclObjectResource,
+				HclObjectName: "si_purge",
+				Name:          strtostrptr("test-si-purge"),
+				Type:          strtostrptr(managedSerivceInstance),
+				Space:         strtostrptr(testSpaceGUID),
+				ServicePlan:   strtostrptr(testServicePanGUID),
+				Parameters:    strtostrptr(testParameters),
+				DeleteMode:    strtostrptr(deleteModePurge),
+			})
+			abandoned = &ServiceInstanceModelPtr{
+				HclType:       hclObjectResource,
+				HclObjectName: "si_abandon",
+				Name:          strtostrptr("test-si-abandon"),
+				Type:          strtostrptr(userProvidedServiceInstance),
+				Space:         strtostrptr(testSpaceGUID),
+				Credentials:   strtostrptr(testCredentials),
+				DeleteMode:    strtostrptr(deleteModeAbandon),
+			}
+		)
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_service_instance_delete_mode")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + purged + hclServiceInstance(abandoned),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr("cloudfoundry_service_instance.si_purge", "delete_mode", deleteModePurge),
+						resource.TestCheckResourceAttr(resourceName, "delete_mode", deleteModeAbandon),
+						resource.TestCheckResourceAttrWith(resourceName, "id", func(value string) error {
+							abandonedID = value
+							return nil
+						}),
+					),
+				},
+				{
+					Config: hclProvider(nil),
+				},
+				{
+					// the abandoned service instance still exists, import it to have it deleted at the end of the test
+					Config: hclProvider(nil) + hclServiceInstance(&ServiceInstanceModelPtr{
+						HclType:       abandoned.HclType,
+						HclObjectName: abandoned.HclObjectName,
+						Name:          abandoned.Name,
+						Type:          abandoned.Type,
+						Space:         abandoned.Space,
+						Credentials:   abandoned.Credentials,
+					}),
+					ResourceName: resourceName,
+					ImportState:  true,
+					ImportStateIdFunc: func(*terraform.State) (string, error) {
+						return abandonedID, nil
+					},
+					ImportStatePersist: true,
+				},
+			},
+		})
+	})
+	t.Run("error path - invalid delete mode", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_service_instance_invalid_delete_mode")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + hclServiceInstance(&ServiceInstanceModelPtr{
+						HclType:       hclObjectResource,
+						HclObjectName: "si_invalid_delete_mode",
+						Name:          strtostrptr("test-si-invalid-delete-mode"),
+						Type:          strtostrptr(userProvidedServiceInstance),
+						Space:         strtostrptr(testSpaceGUID),
+						DeleteMode:    strtostrptr("orphan"),
+					}),
+					ExpectError: regexp.MustCompile(`Invalid Attribute Value Match`),
+				},
+			},
+		})
+	})
+}
 
+func TestMergeManagedParameters(t *testing.T) {
+	t.Parallel()
+	const configured = `{"plan": "standard", "size": 2, "options": {"backup": true}}`
+	tests := []struct {
+		name       string
+		configured string
+		retrieved  string
+		ignoreKeys []string
+		want       string
+	}{
+		{
+			name:       "no drift keeps the configuration",
+			configured: configured,
+			retrieved:  `{"options":{"backup":true},"size":2,"plan":"standard"}`,
+			want:       configured,
+		},
+		{
+			name:       "drift returns the retrieved parameters",
+			configured: configured,
+			retrieved:  `{"plan": "standard", "size": 3, "options": {"backup": true}}`,
+			want:       `{"options":{"backup":true},"plan":"standard","size":3}`,
+		},
+		{
+			name:       "nested drift",
+			configured: configured,
+			retrieved:  `{"plan": "standard", "size": 2, "options": {"backup": false}}`,
+			want:       `{"options":{"backup":false},"plan":"standard","size":2}`,
+		},
+		{
+			name:       "ignored key added by the broker",
+			configured: configured,
+			retrieved:  `{"plan": "standard", "size": 2, "options": {"backup": true}, "region": "eu10"}`,
+			ignoreKeys: []string{"region"},
+			want:       configured,
+		},
+		{
+			name:       "ignored key changed by the broker",
+			configured: configured,
+			retrieved:  `{"plan": "premium", "size": 2, "options": {"backup": true}}`,
+			ignoreKeys: []string{"plan"},
+			want:       configured,
+		},
+		{
+			name:       "ignored keys do not hide other drift",
+			configured: configured,
+			retrieved:  `{"plan": "premium", "size": 4, "options": {"backup": true}, "region": "eu10"}`,
+			ignoreKeys: []string{"plan", "region"},
+			want:       `{"options":{"backup":true},"plan":"standard","size":4}`,
+		},
+		{
+			name:       "key added by the broker is drift unless ignored",
+			configured: configured,
+			retrieved:  `{"plan": "standard", "size": 2, "options": {"backup": true}, "region": "eu10"}`,
+			want:       `{"options":{"backup":true},"plan":"standard","region":"eu10","size":2}`,
+		},
+		{
+			name:       "large numbers keep their precision",
+			configured: `{"quota": 9007199254740993}`,
+			retrieved:  `{"quota": 9007199254740993}`,
+			want:       `{"quota": 9007199254740993}`,
+		},
+		{
+			name:       "retrieved parameters which are no object are ignored",
+			configured: configured,
+			retrieved:  `"standard"`,
+			want:       configured,
+		},
+		{
+			name:       "empty retrieved parameters are ignored",
+			configured: configured,
+			retrieved:  `null`,
+			want:       configured,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, diags := mergeManagedParameters(context.Background(), jsontypes.NewNormalizedValue(tt.configured), []byte(tt.retrieved), tt.ignoreKeys)
+			if diags.HasError() {
+				t.Fatalf("mergeManagedParameters() diagnostics = %v", diags)
+			}
+			if got.ValueString() != tt.want {
+				t.Errorf("mergeManagedParameters() = %s, want %s", got.ValueString(), tt.want)
+			}
+		})
+	}
 }
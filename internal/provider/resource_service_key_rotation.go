@@ -0,0 +1,471 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SAP/terraform-provider-cloudfoundry/internal/provider/managers"
+	"github.com/SAP/terraform-provider-cloudfoundry/internal/validation"
+	cfv3client "github.com/cloudfoundry/go-cfclient/v3/client"
+	cfv3resource "github.com/cloudfoundry/go-cfclient/v3/resource"
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+type serviceKeyRotationResource struct {
+	cfClient *cfv3client.Client
+}
+
+var (
+	_ resource.ResourceWithConfigure      = &serviceKeyRotationResource{}
+	_ resource.ResourceWithImportState    = &serviceKeyRotationResource{}
+	_ resource.ResourceWithValidateConfig = &serviceKeyRotationResource{}
+	_ resource.ResourceWithModifyPlan     = &serviceKeyRotationResource{}
+)
+
+func NewServiceKeyRotationResource() resource.Resource {
+	return &serviceKeyRotationResource{}
+}
+
+func (r *serviceKeyRotationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_service_key_rotation"
+}
+
+func (r *serviceKeyRotationResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Rotates the service keys of a service instance without interrupting their consumers.
+
+Each rotation creates a new service key named after ` + "`name`" + ` with the generation as suffix. The outputs always point at the newest key, so that dependent resources pick it up on the next apply. Older keys are kept until more than ` + "`generations`" + ` keys exist and the newest key has existed for at least ` + "`overlap`" + `.`,
+
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "The base name of the service keys. The keys are named `<name>-<generation>`.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"service_instance": schema.StringAttribute{
+				MarkdownDescription: "The GUID of the service instance for which the service keys are created",
+				Required:            true,
+				Validators: []validator.String{
+					validation.ValidUUID(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"parameters": schema.StringAttribute{
+				MarkdownDescription: "A JSON object that is passed to the service broker when a key is created. Changing it rotates the keys.",
+				Optional:            true,
+				Sensitive:           true,
+				CustomType:          jsontypes.NormalizedType{},
+			},
+			"generations": schema.Int64Attribute{
+				MarkdownDescription: "The number of service keys to keep, including the newest one. Defaults to 2.",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"rotation_period": schema.StringAttribute{
+				MarkdownDescription: "The maximum age of the newest key as a duration like `720h`; a new key is created by the first apply after it has elapsed.",
+				Optional:            true,
+			},
+			"rotation_trigger": schema.StringAttribute{
+				MarkdownDescription: "An arbitrary value; changing it creates a new key.",
+				Optional:            true,
+			},
+			"overlap": schema.StringAttribute{
+				MarkdownDescription: "The time as a duration like `24h` the newest key must have existed before the oldest keys beyond `generations` are deleted. Defaults to `0s`.",
+				Optional:            true,
+			},
+			"generation": schema.Int64Attribute{
+				MarkdownDescription: "The generation of the newest key.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"active_key_id": schema.StringAttribute{
+				MarkdownDescription: "The GUID of the newest key.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"active_key_name": schema.StringAttribute{
+				MarkdownDescription: "The name of the newest key.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"credentials": schema.StringAttribute{
+				MarkdownDescription: "The credentials of the newest key.",
+				Computed:            true,
+				Sensitive:           true,
+				CustomType:          jsontypes.NormalizedType{},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"rotated_at": schema.StringAttribute{
+				MarkdownDescription: "The time at which the newest key was created.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"keys": schema.ListNestedAttribute{
+				MarkdownDescription: "The service keys which currently exist, oldest first.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "The GUID of the service key",
+							Computed:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "The name of the service key",
+							Computed:            true,
+						},
+						"generation": schema.Int64Attribute{
+							MarkdownDescription: "The generation of the service key",
+							Computed:            true,
+						},
+						"created_at": schema.StringAttribute{
+							MarkdownDescription: "The time at which the service key was created",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			idKey: schema.StringAttribute{
+				MarkdownDescription: "The ID of the rotation in the form `<service_instance>/<name>`",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *serviceKeyRotationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	session, ok := req.ProviderData.(*managers.Session)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *managers.Session, got: %T. Please report this issue to the provider developers", req.ProviderData),
+		)
+		return
+	}
+	r.cfClient = session.CFClient
+}
+
+func (r *serviceKeyRotationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config serviceKeyRotationType
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for attribute, value := range map[string]types.String{"rotation_period": config.RotationPeriod, "overlap": config.Overlap} {
+		if value.IsNull() || value.IsUnknown() {
+			continue
+		}
+		duration, err := time.ParseDuration(value.ValueString())
+		if err != nil || duration < 0 || (attribute == "rotation_period" && duration == 0) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attribute),
+				"Invalid duration",
+				fmt.Sprintf("%s must be a positive duration like 24h, got %q", attribute, value.ValueString()),
+			)
+		}
+	}
+}
+
+// ModifyPlan plans a new key if a rotation is due and the deletion of old keys once the newest key has existed for the
+// configured overlap. In both cases the affected computed attributes are marked as unknown, which the update relies on.
+func (r *serviceKeyRotationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+	var plan, state serviceKeyRotationType
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	now := time.Now()
+	rotate, diags := plan.rotationDue(ctx, state, now)
+	resp.Diagnostics.Append(diags...)
+	if rotate {
+		tflog.Info(ctx, "planning rotation of service key", map[string]interface{}{
+			"name":       state.Name.ValueString(),
+			"generation": state.Generation.ValueInt64() + 1,
+		})
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("generation"), types.Int64Unknown())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("active_key_id"), types.StringUnknown())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("active_key_name"), types.StringUnknown())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("credentials"), jsontypes.NewNormalizedUnknown())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("rotated_at"), types.StringUnknown())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("keys"), types.ListUnknown(state.Keys.ElementType(ctx)))...)
+		return
+	}
+	if int64(len(state.Keys.Elements())) > plan.generations() && !now.Before(state.rotatedAt().Add(plan.overlap())) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("keys"), types.ListUnknown(state.Keys.ElementType(ctx)))...)
+	}
+}
+
+func (r *serviceKeyRotationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan serviceKeyRotationType
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keys, err := r.listKeys(ctx, plan)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"API Error in reading service keys",
+			"Unable to list service keys of service instance "+plan.ServiceInstance.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+	var generation int64 = 1
+	if len(keys) > 0 {
+		generation = keys[len(keys)-1].generation + 1
+	}
+	if !r.createKey(ctx, plan, generation, &resp.Diagnostics) {
+		return
+	}
+
+	resp.Diagnostics.Append(r.refresh(ctx, &plan)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *serviceKeyRotationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data serviceKeyRotationType
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keys, err := r.listKeys(ctx, data)
+	if err != nil {
+		handleReadErrors(ctx, resp, err, "service_key_rotation", data.ID.ValueString())
+		return
+	}
+	if len(keys) == 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	resp.Diagnostics.Append(r.mapKeys(ctx, &data, keys)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *serviceKeyRotationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state serviceKeyRotationType
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.ActiveKeyID.IsUnknown() {
+		if !r.createKey(ctx, plan, state.Generation.ValueInt64()+1, &resp.Diagnostics) {
+			resp.State.Raw = req.State.Raw
+			return
+		}
+	}
+
+	if plan.Keys.IsUnknown() {
+		keys, err := r.listKeys(ctx, plan)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"API Error in reading service keys",
+				"Unable to list service keys of service instance "+plan.ServiceInstance.ValueString()+": "+err.Error(),
+			)
+			return
+		}
+		if len(keys) > 0 && !time.Now().Before(keys[len(keys)-1].binding.CreatedAt.Add(plan.overlap())) {
+			for int64(len(keys)) > plan.generations() {
+				if !r.deleteKey(ctx, keys[0].binding, &resp.Diagnostics) {
+					break
+				}
+				keys = keys[1:]
+			}
+		}
+	}
+
+	resp.Diagnostics.Append(r.refresh(ctx, &plan)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *serviceKeyRotationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state serviceKeyRotationType
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keys, err := r.listKeys(ctx, state)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"API Error in reading service keys",
+			"Unable to list service keys of service instance "+state.ServiceInstance.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+	for _, key := range keys {
+		r.deleteKey(ctx, key.binding, &resp.Diagnostics)
+	}
+}
+
+func (r *serviceKeyRotationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	serviceInstance, name, ok := strings.Cut(req.ID, "/")
+	if !ok || serviceInstance == "" || name == "" {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: <service_instance>/<name>. Got: %q", req.ID),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(idKey), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("service_instance"), serviceInstance)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), name)...)
+}
+
+// listKeys returns the existing generations of the rotated service key, oldest first.
+func (r *serviceKeyRotationResource) listKeys(ctx context.Context, data serviceKeyRotationType) ([]serviceKey, error) {
+	bindings, err := r.cfClient.ServiceCredentialBindings.ListAll(ctx, &cfv3client.ServiceCredentialBindingListOptions{
+		ServiceInstanceGUIDs: cfv3client.Filter{
+			Values: []string{
+				data.ServiceInstance.ValueString(),
+			},
+		},
+		Type: cfv3client.Filter{
+			Values: []string{
+				keyServiceCredentialBinding,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return filterServiceKeys(data.Name.ValueString(), bindings), nil
+}
+
+// refresh sets the computed attributes from the service keys which currently exist.
+func (r *serviceKeyRotationResource) refresh(ctx context.Context, data *serviceKeyRotationType) diag.Diagnostics {
+	var diags diag.Diagnostics
+	keys, err := r.listKeys(ctx, *data)
+	if err != nil {
+		diags.AddError(
+			"API Error in reading service keys",
+			"Unable to list service keys of service instance "+data.ServiceInstance.ValueString()+": "+err.Error(),
+		)
+		return diags
+	}
+	if len(keys) == 0 {
+		diags.AddError(
+			"Service keys not found",
+			"No service key "+data.Name.ValueString()+" exists for service instance "+data.ServiceInstance.ValueString(),
+		)
+		return diags
+	}
+	return r.mapKeys(ctx, data, keys)
+}
+
+// mapKeys sets the computed attributes from the given service keys and fetches the credentials of the newest one.
+func (r *serviceKeyRotationResource) mapKeys(ctx context.Context, data *serviceKeyRotationType, keys []serviceKey) diag.Diagnostics {
+	var diags diag.Diagnostics
+	active := keys[len(keys)-1]
+	credentials, err := r.cfClient.ServiceCredentialBindings.GetDetails(ctx, active.binding.GUID)
+	if err != nil {
+		diags.AddWarning(
+			"API Error Fetching Service Credential Binding Details.",
+			fmt.Sprintf("Unable to fetch the credentials of service key %s: %s", *active.binding.Name, err.Error()),
+		)
+	}
+	diags.Append(data.mapServiceKeysToType(ctx, keys, credentials)...)
+	return diags
+}
+
+// createKey creates the service key of the given generation and waits until it is ready.
+func (r *serviceKeyRotationResource) createKey(ctx context.Context, data serviceKeyRotationType, generation int64, diags *diag.Diagnostics) bool {
+	name := data.keyName(generation)
+	createKey := cfv3resource.NewServiceCredentialBindingCreateKey(data.ServiceInstance.ValueString(), name)
+	if !data.Parameters.IsNull() {
+		createKey.WithJSONParameters(data.Parameters.ValueString())
+	}
+
+	jobID, _, err := r.cfClient.ServiceCredentialBindings.Create(ctx, createKey)
+	if err != nil {
+		diags.AddError(
+			"API Error in creating service key",
+			"Unable to create service key "+name+": "+err.Error(),
+		)
+		return false
+	}
+	if jobID != "" {
+		if err := pollJob(ctx, *r.cfClient, jobID, defaultTimeout); err != nil {
+			diags.AddError(
+				"Unable to verify service key creation",
+				"Service key verification failed for "+name+": "+err.Error(),
+			)
+			return false
+		}
+	}
+	tflog.Info(ctx, "created service key", map[string]interface{}{
+		"name": name,
+	})
+	return true
+}
+
+// deleteKey deletes the service key and waits until it is gone.
+func (r *serviceKeyRotationResource) deleteKey(ctx context.Context, key *cfv3resource.ServiceCredentialBinding, diags *diag.Diagnostics) bool {
+	jobID, err := r.cfClient.ServiceCredentialBindings.Delete(ctx, key.GUID)
+	if err != nil {
+		diags.AddError(
+			"API Error in deleting service key",
+			"Unable to delete service key "+*key.Name+": "+err.Error(),
+		)
+		return false
+	}
+	if jobID != "" {
+		if err := pollJob(ctx, *r.cfClient, jobID, defaultTimeout); err != nil {
+			diags.AddError(
+				"Unable to verify service key deletion",
+				"Service key deletion verification failed for "+*key.Name+": "+err.Error(),
+			)
+			return false
+		}
+	}
+	tflog.Info(ctx, "deleted service key", map[string]interface{}{
+		"name": *key.Name,
+	})
+	return true
+}
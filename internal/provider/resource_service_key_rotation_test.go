@@ -0,0 +1,295 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strconv"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+type ServiceKeyRotationModelPtr struct {
+	HclType         string
+	HclObjectName   string
+	Name            *string
+	ServiceInstance *string
+	Parameters      *string
+	Generations     *int
+	RotationPeriod  *string
+	RotationTrigger *string
+	Overlap         *string
+}
+
+func hclServiceKeyRotation(skrmp *ServiceKeyRotationModelPtr) string {
+	if skrmp != nil {
+		s := `
+		{{.HclType}} "cloudfoundry_service_key_rotation" {{.HclObjectName}} {
+			{{- if .Name}}
+				name = "{{.Name}}"
+			{{- end -}}
+			{{if .ServiceInstance}}
+				service_instance = "{{.ServiceInstance}}"
+			{{- end -}}
+			{{if .Parameters}}
+				parameters = <<EOT
+				{{.Parameters}}
+				EOT
+			{{- end -}}
+			{{if .Generations}}
+				generations = {{.Generations}}
+			{{- end -}}
+			{{if .RotationPeriod}}
+				rotation_period = "{{.RotationPeriod}}"
+			{{- end -}}
+			{{if .RotationTrigger}}
+				rotation_trigger = "{{.RotationTrigger}}"
+			{{- end -}}
+			{{if .Overlap}}
+				overlap = "{{.Overlap}}"
+			{{- end }}
+			}`
+		tmpl, err := template.New("resource_service_key_rotation").Parse(s)
+		if err != nil {
+			panic(err)
+		}
+		buf := new(bytes.Buffer)
+		err = tmpl.Execute(buf, skrmp)
+		if err != nil {
+			panic(err)
+		}
+		return buf.String()
+	}
+	return skrmp.HclType + ` "cloudfoundry_service_key_rotation" ` + skrmp.HclObjectName + ` {}`
+}
+
+func TestServiceKeyRotationResource_Configure(t *testing.T) {
+	t.Parallel()
+	t.Run("happy path - create/rotate/delete service key rotation", func(t *testing.T) {
+		var (
+			resourceName = "cloudfoundry_service_key_rotation.rs"
+			rotationName = "tf-test-rotation"
+			// in canary -> PerformanceTeamBLR -> tf-space-1
+			serviceInstanceGUID = "68fea1b6-11b9-4737-ad79-74e49832533f"
+			generations         = 2
+		)
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_service_key_rotation")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + hclServiceKeyRotation(&ServiceKeyRotationModelPtr{
+						HclType:         hclObjectResource,
+						HclObjectName:   "rs",
+						Name:            strtostrptr(rotationName),
+						ServiceInstance: strtostrptr(serviceInstanceGUID),
+						Generations:     &generations,
+						RotationTrigger: strtostrptr("1"),
+					}),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "generation", "1"),
+						resource.TestCheckResourceAttr(resourceName, "active_key_name", rotationName+"-1"),
+						resource.TestMatchResourceAttr(resourceName, "active_key_id", regexpValidUUID),
+						resource.TestCheckResourceAttrSet(resourceName, "credentials"),
+						resource.TestCheckResourceAttr(resourceName, "keys.#", "1"),
+					),
+				},
+				{
+					Config: hclProvider(nil) + hclServiceKeyRotation(&ServiceKeyRotationModelPtr{
+						HclType:         hclObjectResource,
+						HclObjectName:   "rs",
+						Name:            strtostrptr(rotationName),
+						ServiceInstance: strtostrptr(serviceInstanceGUID),
+						Generations:     &generations,
+						RotationTrigger: strtostrptr("2"),
+					}),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "generation", "2"),
+						resource.TestCheckResourceAttr(resourceName, "active_key_name", rotationName+"-2"),
+						resource.TestCheckResourceAttr(resourceName, "keys.#", "2"),
+						resource.TestCheckResourceAttr(resourceName, "keys.0.name", rotationName+"-1"),
+					),
+				},
+				// the oldest key is deleted right away, as the overlap defaults to 0s
+				{
+					Config: hclProvider(nil) + hclServiceKeyRotation(&ServiceKeyRotationModelPtr{
+						HclType:         hclObjectResource,
+						HclObjectName:   "rs",
+						Name:            strtostrptr(rotationName),
+						ServiceInstance: strtostrptr(serviceInstanceGUID),
+						Generations:     &generations,
+						RotationTrigger: strtostrptr("3"),
+					}),
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr(resourceName, "generation", "3"),
+						resource.TestCheckResourceAttr(resourceName, "active_key_name", rotationName+"-3"),
+						resource.TestCheckResourceAttr(resourceName, "keys.#", "2"),
+						resource.TestCheckResourceAttr(resourceName, "keys.0.name", rotationName+"-2"),
+						resource.TestCheckResourceAttr(resourceName, "keys.1.name", rotationName+"-3"),
+					),
+				},
+			},
+		})
+	})
+	t.Run("error path - invalid rotation period", func(t *testing.T) {
+		cfg := getCFHomeConf()
+		rec := cfg.SetupVCR(t, "fixtures/resource_service_key_rotation_invalid_rotation_period")
+		defer stopQuietly(rec)
+		resource.Test(t, resource.TestCase{
+			IsUnitTest:               true,
+			ProtoV6ProviderFactories: getProviders(rec.GetDefaultClient()),
+			Steps: []resource.TestStep{
+				{
+					Config: hclProvider(nil) + hclServiceKeyRotation(&ServiceKeyRotationModelPtr{
+						HclType:         hclObjectResource,
+						HclObjectName:   "rs",
+						Name:            strtostrptr("test-key"),
+						ServiceInstance: strtostrptr("e9ec29ca-993d-42e2-9c5b-cb17b1972cce"),
+						RotationPeriod:  strtostrptr("monthly"),
+					}),
+					ExpectError: regexp.MustCompile(`Invalid duration`),
+				},
+			},
+		})
+	})
+}
+
+const rotationServiceInstanceGUID = "e9ec29ca-993d-42e2-9c5b-cb17b1972cce"
+
+func serviceKeyRotationSchema(t *testing.T, r *serviceKeyRotationResource) schema.Schema {
+	t.Helper()
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(context.Background(), fwresource.SchemaRequest{}, &schemaResp)
+	return schemaResp.Schema
+}
+
+// newServiceKeyRotationState returns the state of a rotation whose newest key is the given generation created at rotatedAt.
+func newServiceKeyRotationState(t *testing.T, s schema.Schema, generation int64, rotatedAt time.Time) tfsdk.State {
+	t.Helper()
+	ctx := context.Background()
+	keys := []serviceKeyType{}
+	for g := int64(1); g <= generation; g++ {
+		keys = append(keys, serviceKeyType{
+			ID:         types.StringValue("key-" + strconv.FormatInt(g, 10)),
+			Name:       types.StringValue("db-" + strconv.FormatInt(g, 10)),
+			Generation: types.Int64Value(g),
+			CreatedAt:  types.StringValue(rotatedAt.UTC().Format(time.RFC3339)),
+		})
+	}
+	keyList, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: serviceKeyAttrTypes}, keys)
+	data := serviceKeyRotationType{
+		ID:              types.StringValue(rotationServiceInstanceGUID + "/db"),
+		Name:            types.StringValue("db"),
+		ServiceInstance: types.StringValue(rotationServiceInstanceGUID),
+		Parameters:      jsontypes.NewNormalizedNull(),
+		Generations:     types.Int64Value(2),
+		RotationPeriod:  types.StringValue("720h"),
+		RotationTrigger: types.StringNull(),
+		Overlap:         types.StringValue("1h"),
+		Generation:      types.Int64Value(generation),
+		ActiveKeyID:     types.StringValue("key-" + strconv.FormatInt(generation, 10)),
+		ActiveKeyName:   types.StringValue("db-" + strconv.FormatInt(generation, 10)),
+		Credentials:     jsontypes.NewNormalizedValue(`{"password":"secret"}`),
+		RotatedAt:       types.StringValue(rotatedAt.UTC().Format(time.RFC3339)),
+		Keys:            keyList,
+	}
+	state := tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(ctx), nil)}
+	diags.Append(state.Set(ctx, &data)...)
+	if diags.HasError() {
+		t.Fatalf("building state: %v", diags)
+	}
+	return state
+}
+
+func TestServiceKeyRotationResource_ModifyPlan(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	r := &serviceKeyRotationResource{}
+	s := serviceKeyRotationSchema(t, r)
+
+	tests := []struct {
+		name       string
+		generation int64
+		rotatedAt  time.Time
+		trigger    string
+		wantRotate bool
+		wantKeys   bool
+	}{
+		{
+			name:       "rotation not due",
+			generation: 2,
+			rotatedAt:  time.Now().Add(-24 * time.Hour),
+		},
+		{
+			name:       "rotation period elapsed",
+			generation: 2,
+			rotatedAt:  time.Now().Add(-721 * time.Hour),
+			wantRotate: true,
+			wantKeys:   true,
+		},
+		{
+			name:       "rotation triggered",
+			generation: 2,
+			rotatedAt:  time.Now().Add(-24 * time.Hour),
+			trigger:    "2026-10",
+			wantRotate: true,
+			wantKeys:   true,
+		},
+		{
+			name:       "old keys within overlap",
+			generation: 3,
+			rotatedAt:  time.Now().Add(-30 * time.Minute),
+		},
+		{
+			name:       "old keys after overlap",
+			generation: 3,
+			rotatedAt:  time.Now().Add(-2 * time.Hour),
+			wantKeys:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newServiceKeyRotationState(t, s, tt.generation, tt.rotatedAt)
+			plan := tfsdk.Plan{Schema: s, Raw: state.Raw.Copy()}
+			if tt.trigger != "" {
+				if diags := plan.SetAttribute(ctx, path.Root("rotation_trigger"), tt.trigger); diags.HasError() {
+					t.Fatalf("setting rotation_trigger: %v", diags)
+				}
+			}
+			resp := fwresource.ModifyPlanResponse{Plan: plan}
+			r.ModifyPlan(ctx, fwresource.ModifyPlanRequest{State: state, Plan: plan}, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("ModifyPlan() diagnostics = %v", resp.Diagnostics)
+			}
+
+			var planned serviceKeyRotationType
+			resp.Diagnostics.Append(resp.Plan.Get(ctx, &planned)...)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("reading plan: %v", resp.Diagnostics)
+			}
+			if rotate := planned.ActiveKeyID.IsUnknown(); rotate != tt.wantRotate {
+				t.Errorf("rotation planned = %t, want %t", rotate, tt.wantRotate)
+			}
+			if planned.Generation.IsUnknown() != tt.wantRotate || planned.Credentials.IsUnknown() != tt.wantRotate || planned.RotatedAt.IsUnknown() != tt.wantRotate {
+				t.Errorf("generation, credentials and rotated_at unknown = %t, %t, %t, want %t",
+					planned.Generation.IsUnknown(), planned.Credentials.IsUnknown(), planned.RotatedAt.IsUnknown(), tt.wantRotate)
+			}
+			if keys := planned.Keys.IsUnknown(); keys != tt.wantKeys {
+				t.Errorf("keys unknown = %t, want %t", keys, tt.wantKeys)
+			}
+		})
+	}
+}
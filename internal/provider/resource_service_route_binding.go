@@ -14,6 +14,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 type serviceRouteBindingResource struct {
@@ -23,6 +24,7 @@ type serviceRouteBindingResource struct {
 var (
 	_ resource.ResourceWithConfigure   = &serviceRouteBindingResource{}
 	_ resource.ResourceWithImportState = &serviceRouteBindingResource{}
+	_ resource.ResourceWithModifyPlan  = &serviceRouteBindingResource{}
 )
 
 func NewServiceRouteBindingResource() resource.Resource {
@@ -91,6 +93,15 @@ func (r *serviceRouteBindingResource) Configure(ctx context.Context, req resourc
 	r.cfClient = session.CFClient
 }
 
+// ModifyPlan picks up unfinished or failed operations of earlier applies.
+func (r *serviceRouteBindingResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.cfClient == nil {
+		return
+	}
+	replaceFailedCreation(ctx, req, resp)
+	planPendingJob(ctx, req.Private, req, resp, path.Root("last_operation"), types.ObjectUnknown(lastOperationAttrTypes))
+}
+
 func (r *serviceRouteBindingResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var (
 		plan                serviceRouteBindingType
@@ -115,8 +126,14 @@ func (r *serviceRouteBindingResource) Create(ctx context.Context, req resource.C
 		return
 
 	} else if jobID != "" {
-		err = pollJob(ctx, *r.cfClient, jobID, defaultTimeout)
-		if err != nil {
+		err = awaitJob(ctx, *r.cfClient, resp.Private, pendingJob{GUID: jobID, Operation: jobOperationCreate}, defaultTimeout, &resp.Diagnostics)
+		if isJobTimeout(err) {
+			// The pending job stays in the private state, so the next apply waits for it before the binding is replaced
+			resp.Diagnostics.AddError(
+				"Service route binding creation still in progress",
+				"The creation of the service route binding with route "+plan.Route.ValueString()+" did not finish within the timeout. The next apply awaits its creation before the binding is replaced.",
+			)
+		} else if err != nil {
 			resp.Diagnostics.AddError(
 				"Unable to verify service Route binding creation",
 				"Service Route Binding verification failed for Route "+plan.Route.ValueString()+": "+err.Error(),
@@ -166,6 +183,7 @@ func (r *serviceRouteBindingResource) Read(ctx context.Context, req resource.Rea
 		handleReadErrors(ctx, resp, err, "service_route_binding", data.ID.ValueString())
 		return
 	}
+	refreshPendingJob(ctx, *r.cfClient, resp.Private, &resp.Diagnostics)
 
 	state, diags := mapServiceRouteBindingValuesToType(ctx, serviceRouteBinding)
 	state.Parameters = data.Parameters
@@ -183,6 +201,10 @@ func (r *serviceRouteBindingResource) Update(ctx context.Context, req resource.U
 		return
 	}
 
+	if _, err := resumePendingJob(ctx, *r.cfClient, req, resp, defaultTimeout); err != nil {
+		return
+	}
+
 	updateServiceRouteBinding, diags := plan.mapUpdateServiceRouteBindingTypeToValues(ctx, previousState)
 	resp.Diagnostics.Append(diags...)
 
@@ -209,16 +231,27 @@ func (r *serviceRouteBindingResource) Delete(ctx context.Context, req resource.D
 		return
 	}
 
-	jobID, err := r.cfClient.ServiceRouteBindings.Delete(ctx, state.ID.ValueString())
-	if err != nil {
+	if err := awaitPendingChange(ctx, *r.cfClient, resp.Private, defaultTimeout, &resp.Diagnostics); err != nil {
 		resp.Diagnostics.AddError(
-			"API Error in deleting service route binding",
-			"Unable to delete route binding "+state.ID.ValueString()+": "+err.Error(),
+			"Service route binding operation still in progress",
+			"Unable to delete route binding "+state.ID.ValueString()+" as its pending operation did not finish: "+err.Error(),
 		)
+		return
+	}
+	jobID := pendingDeleteJob(ctx, req.Private, &resp.Diagnostics)
+	if jobID == "" {
+		var err error
+		jobID, err = r.cfClient.ServiceRouteBindings.Delete(ctx, state.ID.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"API Error in deleting service route binding",
+				"Unable to delete route binding "+state.ID.ValueString()+": "+err.Error(),
+			)
 
+		}
 	}
 	if jobID != "" {
-		if err := pollJob(ctx, *r.cfClient, jobID, defaultTimeout); err != nil {
+		if err := awaitJob(ctx, *r.cfClient, resp.Private, pendingJob{GUID: jobID, Operation: jobOperationDelete}, defaultTimeout, &resp.Diagnostics); err != nil {
 			resp.Diagnostics.AddError(
 				"Unable to verify service route binding deletion",
 				"service route binding deletion verification failed for "+state.ID.ValueString()+": "+err.Error(),
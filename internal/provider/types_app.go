@@ -6,6 +6,11 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,46 +20,57 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gopkg.in/yaml.v2"
 )
 
 // Type AppType representing Schema Attribute from function Schema in go type from resource_appManifest.go file.
 type AppType struct {
-	Name                                  types.String       `tfsdk:"name"`
-	Space                                 types.String       `tfsdk:"space_name"`
-	Org                                   types.String       `tfsdk:"org_name"`
-	Stack                                 types.String       `tfsdk:"stack"`
-	Buildpacks                            types.Set          `tfsdk:"buildpacks"`
-	Path                                  types.String       `tfsdk:"path"`
-	SourceCodeHash                        types.String       `tfsdk:"source_code_hash"`
-	DockerImage                           types.String       `tfsdk:"docker_image"`
-	DockerCredentials                     *DockerCredentials `tfsdk:"docker_credentials"`
-	Strategy                              types.String       `tfsdk:"strategy"`
-	ServiceBindings                       []ServiceBinding   `tfsdk:"service_bindings"`
-	Routes                                types.Set          `tfsdk:"routes"`
-	Environment                           types.Map          `tfsdk:"environment"`
-	HealthCheckInterval                   types.Int64        `tfsdk:"health_check_interval"`
-	ReadinessHealthCheckType              types.String       `tfsdk:"readiness_health_check_type"`
-	ReadinessHealthCheckHttpEndpoint      types.String       `tfsdk:"readiness_health_check_http_endpoint"`
-	ReadinessHealthCheckInvocationTimeout types.Int64        `tfsdk:"readiness_health_check_invocation_timeout"`
-	ReadinessHealthCheckInterval          types.Int64        `tfsdk:"readiness_health_check_interval"`
-	LogRateLimitPerSecond                 types.String       `tfsdk:"log_rate_limit_per_second"`
-	NoRoute                               types.Bool         `tfsdk:"no_route"`
-	RandomRoute                           types.Bool         `tfsdk:"random_route"`
-	Processes                             []Process          `tfsdk:"processes"`
-	Sidecars                              []Sidecar          `tfsdk:"sidecars"`
-	ID                                    types.String       `tfsdk:"id"`
-	CreatedAt                             types.String       `tfsdk:"created_at"`
-	UpdatedAt                             types.String       `tfsdk:"updated_at"`
-	Command                               types.String       `tfsdk:"command"`
-	DiskQuota                             types.String       `tfsdk:"disk_quota"`
-	HealthCheckHttpEndpoint               types.String       `tfsdk:"health_check_http_endpoint"`
-	HealthCheckInvocationTimeout          types.Int64        `tfsdk:"health_check_invocation_timeout"`
-	HealthCheckType                       types.String       `tfsdk:"health_check_type"`
-	Instances                             types.Int64        `tfsdk:"instances"`
-	Memory                                types.String       `tfsdk:"memory"`
-	Timeout                               types.Int64        `tfsdk:"timeout"`
-	Labels                                types.Map          `tfsdk:"labels"`
-	Annotations                           types.Map          `tfsdk:"annotations"`
+	Name                                  types.String         `tfsdk:"name"`
+	Space                                 types.String         `tfsdk:"space_name"`
+	Org                                   types.String         `tfsdk:"org_name"`
+	Stack                                 types.String         `tfsdk:"stack"`
+	Lifecycle                             types.String         `tfsdk:"lifecycle_type"`
+	CNBCredentials                        jsontypes.Normalized `tfsdk:"cnb_credentials"`
+	Buildpacks                            types.Set            `tfsdk:"buildpacks"`
+	Path                                  types.String         `tfsdk:"path"`
+	SourceCodeHash                        types.String         `tfsdk:"source_code_hash"`
+	DockerImage                           types.String         `tfsdk:"docker_image"`
+	DockerCredentials                     *DockerCredentials   `tfsdk:"docker_credentials"`
+	Strategy                              types.String         `tfsdk:"strategy"`
+	ZeroDowntimeSpaceMove                 types.Bool           `tfsdk:"zero_downtime_space_move"`
+	State                                 types.String         `tfsdk:"state"`
+	RestartTriggers                       types.Map            `tfsdk:"restart_triggers"`
+	ServiceBindings                       []ServiceBinding     `tfsdk:"service_bindings"`
+	Routes                                types.Set            `tfsdk:"routes"`
+	Environment                           types.Map            `tfsdk:"environment"`
+	HealthCheckInterval                   types.Int64          `tfsdk:"health_check_interval"`
+	ReadinessHealthCheckType              types.String         `tfsdk:"readiness_health_check_type"`
+	ReadinessHealthCheckHttpEndpoint      types.String         `tfsdk:"readiness_health_check_http_endpoint"`
+	ReadinessHealthCheckInvocationTimeout types.Int64          `tfsdk:"readiness_health_check_invocation_timeout"`
+	ReadinessHealthCheckInterval          types.Int64          `tfsdk:"readiness_health_check_interval"`
+	LogRateLimitPerSecond                 types.String         `tfsdk:"log_rate_limit_per_second"`
+	NoRoute                               types.Bool           `tfsdk:"no_route"`
+	RandomRoute                           types.Bool           `tfsdk:"random_route"`
+	Processes                             []Process            `tfsdk:"processes"`
+	Sidecars                              []Sidecar            `tfsdk:"sidecars"`
+	ID                                    types.String         `tfsdk:"id"`
+	CreatedAt                             types.String         `tfsdk:"created_at"`
+	UpdatedAt                             types.String         `tfsdk:"updated_at"`
+	Command                               types.String         `tfsdk:"command"`
+	DiskQuota                             types.String         `tfsdk:"disk_quota"`
+	HealthCheckHttpEndpoint               types.String         `tfsdk:"health_check_http_endpoint"`
+	HealthCheckInvocationTimeout          types.Int64          `tfsdk:"health_check_invocation_timeout"`
+	HealthCheckType                       types.String         `tfsdk:"health_check_type"`
+	Instances                             types.Int64          `tfsdk:"instances"`
+	Memory                                types.String         `tfsdk:"memory"`
+	Timeout                               types.Int64          `tfsdk:"timeout"`
+	Labels                                types.Map            `tfsdk:"labels"`
+	Annotations                           types.Map            `tfsdk:"annotations"`
+	WaitFor                               *AppWaitFor          `tfsdk:"wait_for"`
+	Manifest                              types.String         `tfsdk:"manifest"`
+	ManifestPath                          types.String         `tfsdk:"manifest_path"`
+	Vars                                  types.Map            `tfsdk:"vars"`
+	VarsFiles                             types.List           `tfsdk:"vars_files"`
 }
 
 type DatasourceAppType struct {
@@ -62,6 +78,7 @@ type DatasourceAppType struct {
 	Space                                 types.String       `tfsdk:"space_name"`
 	Org                                   types.String       `tfsdk:"org_name"`
 	Stack                                 types.String       `tfsdk:"stack"`
+	Lifecycle                             types.String       `tfsdk:"lifecycle_type"`
 	Buildpacks                            types.Set          `tfsdk:"buildpacks"`
 	DockerImage                           types.String       `tfsdk:"docker_image"`
 	DockerCredentials                     *DockerCredentials `tfsdk:"docker_credentials"`
@@ -105,6 +122,45 @@ func (a *DatasourceAppType) Expand() AppType {
 	return expanded
 }
 
+// cnbManifest wraps the app manifest as the cf-client manifest has no notion of the cnb lifecycle.
+type cnbManifest struct {
+	Applications []*cnbAppManifest `yaml:"applications"`
+}
+
+type cnbAppManifest struct {
+	cfv3operation.AppManifest `yaml:",inline"`
+	Lifecycle                 string `yaml:"lifecycle"`
+}
+
+type cnbRegistryCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type cnbLifecycleUpdate struct {
+	Lifecycle struct {
+		Type string `json:"type"`
+		Data struct {
+			Buildpacks  []string                          `json:"buildpacks,omitempty"`
+			Stack       string                            `json:"stack,omitempty"`
+			Credentials map[string]cnbRegistryCredentials `json:"credentials,omitempty"`
+		} `json:"data"`
+	} `json:"lifecycle"`
+}
+
+type AppWaitFor struct {
+	MinRunningInstances types.Map      `tfsdk:"min_running_instances"`
+	Timeout             types.Int64    `tfsdk:"timeout"`
+	SmokeCheck          *AppSmokeCheck `tfsdk:"smoke_check"`
+}
+
+type AppSmokeCheck struct {
+	Route          types.String `tfsdk:"route"`
+	Path           types.String `tfsdk:"path"`
+	ExpectedStatus types.Int64  `tfsdk:"expected_status"`
+	ExpectedBody   types.String `tfsdk:"expected_body"`
+}
+
 type Sidecar struct {
 	Name         types.String `tfsdk:"name"`
 	Command      types.String `tfsdk:"command"`
@@ -457,7 +513,9 @@ func mapAppValuesToType(ctx context.Context, appManifest *cfv3operation.AppManif
 				if process.HealthCheckInvocationTimeout != 0 {
 					appType.HealthCheckInvocationTimeout = types.Int64Value(int64(process.HealthCheckInvocationTimeout))
 				}
-				appType.Instances = types.Int64Value(int64(*process.Instances))
+				if process.Instances != nil {
+					appType.Instances = types.Int64Value(int64(*process.Instances))
+				}
 				if process.Memory != "" {
 					if !reqPlanType.Memory.IsNull() && !reqPlanType.Memory.IsUnknown() {
 						result, err := getDesiredType(process.Memory, reqPlanType.Memory.ValueString())
@@ -507,7 +565,7 @@ func mapAppValuesToType(ctx context.Context, appManifest *cfv3operation.AppManif
 					p.Command = types.StringValue(process.Command)
 				}
 				if process.DiskQuota != "" {
-					if reqPlanType != nil && !reqPlanType.Processes[i].DiskQuota.IsNull() && !reqPlanType.Processes[i].DiskQuota.IsUnknown() {
+					if reqPlanType != nil && len(reqPlanType.Processes) > i && !reqPlanType.Processes[i].DiskQuota.IsNull() && !reqPlanType.Processes[i].DiskQuota.IsUnknown() {
 						result, err := getDesiredType(process.DiskQuota, reqPlanType.Processes[i].DiskQuota.ValueString())
 						if err != nil {
 							tempDiags.AddError("Error converting disk quota", err.Error())
@@ -527,9 +585,11 @@ func mapAppValuesToType(ctx context.Context, appManifest *cfv3operation.AppManif
 				if process.HealthCheckType != "" {
 					p.HealthCheckType = types.StringValue(string(process.HealthCheckType))
 				}
-				p.Instances = types.Int64Value(int64(*process.Instances))
+				if process.Instances != nil {
+					p.Instances = types.Int64Value(int64(*process.Instances))
+				}
 				if process.Memory != "" {
-					if reqPlanType != nil && !reqPlanType.Processes[i].Memory.IsNull() && !reqPlanType.Processes[i].Memory.IsUnknown() {
+					if reqPlanType != nil && len(reqPlanType.Processes) > i && !reqPlanType.Processes[i].Memory.IsNull() && !reqPlanType.Processes[i].Memory.IsUnknown() {
 						result, err := getDesiredType(process.Memory, reqPlanType.Processes[i].Memory.ValueString())
 						if err != nil {
 							tempDiags.AddError("Error converting memory", err.Error())
@@ -559,7 +619,7 @@ func mapAppValuesToType(ctx context.Context, appManifest *cfv3operation.AppManif
 					p.ReadinessHealthCheckInterval = types.Int64Value(int64(process.ReadinessHealthCheckInterval))
 				}
 				if process.LogRateLimitPerSecond != "" {
-					if reqPlanType != nil && !reqPlanType.Processes[i].LogRateLimitPerSecond.IsNull() && !reqPlanType.Processes[i].LogRateLimitPerSecond.IsUnknown() {
+					if reqPlanType != nil && len(reqPlanType.Processes) > i && !reqPlanType.Processes[i].LogRateLimitPerSecond.IsNull() && !reqPlanType.Processes[i].LogRateLimitPerSecond.IsUnknown() {
 						result, err := getDesiredType(process.LogRateLimitPerSecond, reqPlanType.Processes[i].LogRateLimitPerSecond.ValueString())
 						if err != nil {
 							tempDiags.AddError("Error converting log_rate_limit", err.Error())
@@ -590,7 +650,7 @@ func mapAppValuesToType(ctx context.Context, appManifest *cfv3operation.AppManif
 				s.ProcessTypes = types.SetNull(types.StringType)
 			}
 			if sidecar.Memory != "" {
-				if !reqPlanType.Sidecars[i].Memory.IsUnknown() {
+				if reqPlanType != nil && len(reqPlanType.Sidecars) > i && !reqPlanType.Sidecars[i].Memory.IsUnknown() {
 					result, err := getDesiredType(sidecar.Memory, reqPlanType.Sidecars[i].Memory.ValueString())
 					if err != nil {
 						tempDiags.AddError("Error converting memory", err.Error())
@@ -605,6 +665,8 @@ func mapAppValuesToType(ctx context.Context, appManifest *cfv3operation.AppManif
 		}
 		appType.Sidecars = sidecars
 	}
+	appType.Lifecycle = types.StringValue(app.Lifecycle.Type)
+	appType.State = types.StringValue(app.State)
 	appType.ID = types.StringValue(app.GUID)
 	appType.CreatedAt = types.StringValue(app.CreatedAt.Format(time.RFC3339))
 	appType.UpdatedAt = types.StringValue(app.UpdatedAt.Format(time.RFC3339))
@@ -624,6 +686,14 @@ func (target *AppType) CopyConfigAttributes(source *AppType) {
 	target.SourceCodeHash = source.SourceCodeHash
 	target.RandomRoute = source.RandomRoute
 	target.NoRoute = source.NoRoute
+	target.CNBCredentials = source.CNBCredentials
+	target.WaitFor = source.WaitFor
+	target.RestartTriggers = source.RestartTriggers
+	target.ZeroDowntimeSpaceMove = source.ZeroDowntimeSpaceMove
+	target.Manifest = source.Manifest
+	target.ManifestPath = source.ManifestPath
+	target.Vars = source.Vars
+	target.VarsFiles = source.VarsFiles
 }
 
 func getDesiredType(actual string, desired string) (string, error) {
@@ -685,3 +755,310 @@ func splitValueAndUnit(value string) (float64, string, error) {
 	}
 	return val, unit, nil
 }
+
+func (appType *AppType) usesManifest() bool {
+	return !appType.Manifest.IsNull() || !appType.ManifestPath.IsNull()
+}
+
+// requiresPush reports whether the plan changes more than the name, the running state or the restart triggers of the app.
+// Unknown values are computed by the push and hence do not count as a change on their own.
+func (plan *AppType) requiresPush(state *AppType) bool {
+	pushAttributes := [][2]attr.Value{
+		{plan.Space, state.Space},
+		{plan.Org, state.Org},
+		{plan.Stack, state.Stack},
+		{plan.Lifecycle, state.Lifecycle},
+		{plan.CNBCredentials, state.CNBCredentials},
+		{plan.Buildpacks, state.Buildpacks},
+		{plan.Path, state.Path},
+		{plan.SourceCodeHash, state.SourceCodeHash},
+		{plan.DockerImage, state.DockerImage},
+		{plan.Strategy, state.Strategy},
+		{plan.Routes, state.Routes},
+		{plan.Environment, state.Environment},
+		{plan.HealthCheckInterval, state.HealthCheckInterval},
+		{plan.ReadinessHealthCheckType, state.ReadinessHealthCheckType},
+		{plan.ReadinessHealthCheckHttpEndpoint, state.ReadinessHealthCheckHttpEndpoint},
+		{plan.ReadinessHealthCheckInvocationTimeout, state.ReadinessHealthCheckInvocationTimeout},
+		{plan.ReadinessHealthCheckInterval, state.ReadinessHealthCheckInterval},
+		{plan.LogRateLimitPerSecond, state.LogRateLimitPerSecond},
+		{plan.NoRoute, state.NoRoute},
+		{plan.RandomRoute, state.RandomRoute},
+		{plan.Command, state.Command},
+		{plan.DiskQuota, state.DiskQuota},
+		{plan.HealthCheckHttpEndpoint, state.HealthCheckHttpEndpoint},
+		{plan.HealthCheckInvocationTimeout, state.HealthCheckInvocationTimeout},
+		{plan.HealthCheckType, state.HealthCheckType},
+		{plan.Instances, state.Instances},
+		{plan.Memory, state.Memory},
+		{plan.Timeout, state.Timeout},
+		{plan.Labels, state.Labels},
+		{plan.Annotations, state.Annotations},
+		{plan.Manifest, state.Manifest},
+		{plan.ManifestPath, state.ManifestPath},
+		{plan.Vars, state.Vars},
+		{plan.VarsFiles, state.VarsFiles},
+	}
+	for _, values := range pushAttributes {
+		planValue, stateValue := values[0], values[1]
+		if planValue.IsUnknown() || (planValue.IsNull() && stateValue.IsNull()) {
+			continue
+		}
+		if !planValue.Equal(stateValue) {
+			return true
+		}
+	}
+	return !reflect.DeepEqual(plan.DockerCredentials, state.DockerCredentials) ||
+		!reflect.DeepEqual(plan.ServiceBindings, state.ServiceBindings) ||
+		!reflect.DeepEqual(plan.Processes, state.Processes) ||
+		!reflect.DeepEqual(plan.Sidecars, state.Sidecars)
+}
+
+// manifestVarRegexp matches the ((variable)) placeholders of a cf manifest.
+var manifestVarRegexp = regexp.MustCompile(`\(\(([-/\.\w\pL]+)\)\)`)
+
+// loadAppManifest reads the manifest of the app, substitutes the variables and returns the application matching the app name.
+func (appType *AppType) loadAppManifest(ctx context.Context) (*cfv3operation.AppManifest, error) {
+	raw := appType.Manifest.ValueString()
+	baseDir := ""
+	if !appType.ManifestPath.IsNull() {
+		content, err := os.ReadFile(appType.ManifestPath.ValueString())
+		if err != nil {
+			return nil, fmt.Errorf("unable to read manifest: %w", err)
+		}
+		raw = string(content)
+		baseDir = filepath.Dir(appType.ManifestPath.ValueString())
+	}
+
+	vars := map[string]interface{}{}
+	if !appType.VarsFiles.IsNull() {
+		var varsFiles []string
+		if diags := appType.VarsFiles.ElementsAs(ctx, &varsFiles, false); diags.HasError() {
+			return nil, fmt.Errorf("invalid vars_files")
+		}
+		for _, varsFile := range varsFiles {
+			content, err := os.ReadFile(varsFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read vars file: %w", err)
+			}
+			var fileVars map[string]interface{}
+			if err = yaml.Unmarshal(content, &fileVars); err != nil {
+				return nil, fmt.Errorf("unable to parse vars file %s: %w", varsFile, err)
+			}
+			for k, v := range fileVars {
+				vars[k] = v
+			}
+		}
+	}
+	if !appType.Vars.IsNull() {
+		var inlineVars map[string]string
+		if diags := appType.Vars.ElementsAs(ctx, &inlineVars, false); diags.HasError() {
+			return nil, fmt.Errorf("invalid vars")
+		}
+		for k, v := range inlineVars {
+			vars[k] = v
+		}
+	}
+
+	rendered, err := interpolateManifestVars(raw, vars)
+	if err != nil {
+		return nil, err
+	}
+	var manifest cfv3operation.Manifest
+	if err = yaml.Unmarshal([]byte(rendered), &manifest); err != nil {
+		return nil, fmt.Errorf("unable to parse manifest: %w", err)
+	}
+	var app *cfv3operation.AppManifest
+	for _, a := range manifest.Applications {
+		if a != nil && a.Name == appType.Name.ValueString() {
+			app = a
+		}
+	}
+	// a single application in the manifest gets the name of the resource, like cf push <name> does
+	if app == nil && len(manifest.Applications) == 1 && manifest.Applications[0] != nil {
+		app = manifest.Applications[0]
+	}
+	if app == nil {
+		return nil, fmt.Errorf("application %s not found in manifest", appType.Name.ValueString())
+	}
+	app.Name = appType.Name.ValueString()
+	if app.Path != "" && !filepath.IsAbs(app.Path) {
+		app.Path = filepath.Join(baseDir, app.Path)
+	}
+	return app, nil
+}
+
+// interpolateManifestVars replaces all ((variable)) placeholders and fails on variables without a value.
+func interpolateManifestVars(raw string, vars map[string]interface{}) (string, error) {
+	var missing []string
+	var renderErr error
+	rendered := manifestVarRegexp.ReplaceAllStringFunc(raw, func(match string) string {
+		name := manifestVarRegexp.FindStringSubmatch(match)[1]
+		value, ok := vars[name]
+		if !ok {
+			if !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+			return match
+		}
+		switch v := value.(type) {
+		case map[interface{}]interface{}, []interface{}:
+			// complex values are rendered as json which is valid yaml flow style
+			encoded, err := json.Marshal(toJSONCompatible(v))
+			if err != nil {
+				renderErr = fmt.Errorf("unable to render variable %s: %w", name, err)
+			}
+			return string(encoded)
+		default:
+			return fmt.Sprint(v)
+		}
+	})
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return "", fmt.Errorf("expected to find variables: %s", strings.Join(missing, ", "))
+	}
+	return rendered, renderErr
+}
+
+// toJSONCompatible converts the generic maps of yaml.v2 to maps with string keys.
+func toJSONCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = toJSONCompatible(val)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = toJSONCompatible(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// mergeAppManifests overlays the manifest derived from the resource attributes onto the application of the manifest file,
+// so that every configured attribute wins over the corresponding top level key of the manifest.
+func mergeAppManifests(base *cfv3operation.AppManifest, override *cfv3operation.AppManifest) (*cfv3operation.AppManifest, error) {
+	merged := map[string]interface{}{}
+	for _, m := range []*cfv3operation.AppManifest{base, override} {
+		raw, err := yaml.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		var values map[string]interface{}
+		if err = yaml.Unmarshal(raw, &values); err != nil {
+			return nil, err
+		}
+		for k, v := range values {
+			merged[k] = v
+		}
+	}
+	raw, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	var app cfv3operation.AppManifest
+	if err = yaml.Unmarshal(raw, &app); err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+// normalizeAppManifest moves the app level process attributes into the web process like the manifest generated by cf.
+func normalizeAppManifest(app *cfv3operation.AppManifest) *cfv3operation.AppManifest {
+	normalized := *app
+	if normalized.Processes == nil && normalized.AppManifestProcess != (cfv3operation.AppManifestProcess{}) {
+		web := normalized.AppManifestProcess
+		web.Type = cfv3operation.Web
+		normalized.Processes = &cfv3operation.AppManifestProcesses{web}
+		normalized.AppManifestProcess = cfv3operation.AppManifestProcess{}
+	}
+	return &normalized
+}
+
+// applyManifestValues plans the computed attributes which are not configured with the values of the manifest so that drift shows up.
+func (plan *AppType) applyManifestValues(ctx context.Context, manifestType *AppType, config *AppType) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if config.Stack.IsNull() && manifestType.Stack.ValueString() != "" {
+		plan.Stack = manifestType.Stack
+	}
+	if config.Buildpacks.IsNull() && !manifestType.Buildpacks.IsNull() {
+		plan.Buildpacks = manifestType.Buildpacks
+	}
+	if config.Routes.IsNull() && !manifestType.Routes.IsNull() {
+		var routes []Route
+		diags.Append(manifestType.Routes.ElementsAs(ctx, &routes, false)...)
+		for i := range routes {
+			// the protocol defaults on the cf side
+			if routes[i].Protocol.IsNull() {
+				routes[i].Protocol = types.StringUnknown()
+			}
+		}
+		var tempDiags diag.Diagnostics
+		plan.Routes, tempDiags = types.SetValueFrom(ctx, routeObjType, routes)
+		diags.Append(tempDiags...)
+	}
+	if len(config.Processes) != 0 {
+		return diags
+	}
+	if config.DiskQuota.IsNull() && !manifestType.DiskQuota.IsNull() {
+		plan.DiskQuota = manifestType.DiskQuota
+	}
+	if config.HealthCheckType.IsNull() && !manifestType.HealthCheckType.IsNull() {
+		plan.HealthCheckType = manifestType.HealthCheckType
+	}
+	if config.ReadinessHealthCheckType.IsNull() && !manifestType.ReadinessHealthCheckType.IsNull() {
+		plan.ReadinessHealthCheckType = manifestType.ReadinessHealthCheckType
+	}
+	if config.LogRateLimitPerSecond.IsNull() && !manifestType.LogRateLimitPerSecond.IsNull() {
+		plan.LogRateLimitPerSecond = manifestType.LogRateLimitPerSecond
+	}
+	if config.Instances.IsNull() && !manifestType.Instances.IsNull() {
+		plan.Instances = manifestType.Instances
+	}
+	if config.Memory.IsNull() && !manifestType.Memory.IsNull() {
+		plan.Memory = manifestType.Memory
+	}
+	return diags
+}
+
+// maskManifestAttributes resets the attributes which are not configured but set through the manifest. With manifestType given,
+// values that differ from the manifest are kept so that the drift shows up in the plan.
+func (target *AppType) maskManifestAttributes(source *AppType, manifestType *AppType) {
+	manifest := manifestType
+	if manifest == nil {
+		// without a manifest to compare with every unconfigured value is reset
+		manifest = target
+	}
+	maskManifestValue(&target.Environment, source.Environment, manifest.Environment)
+	maskManifestValue(&target.Command, source.Command, manifest.Command)
+	maskManifestValue(&target.DockerImage, source.DockerImage, manifest.DockerImage)
+	maskManifestValue(&target.Timeout, source.Timeout, manifest.Timeout)
+	maskManifestValue(&target.HealthCheckHttpEndpoint, source.HealthCheckHttpEndpoint, manifest.HealthCheckHttpEndpoint)
+	maskManifestValue(&target.HealthCheckInvocationTimeout, source.HealthCheckInvocationTimeout, manifest.HealthCheckInvocationTimeout)
+	maskManifestValue(&target.HealthCheckInterval, source.HealthCheckInterval, manifest.HealthCheckInterval)
+	maskManifestValue(&target.ReadinessHealthCheckHttpEndpoint, source.ReadinessHealthCheckHttpEndpoint, manifest.ReadinessHealthCheckHttpEndpoint)
+	maskManifestValue(&target.ReadinessHealthCheckInvocationTimeout, source.ReadinessHealthCheckInvocationTimeout, manifest.ReadinessHealthCheckInvocationTimeout)
+	maskManifestValue(&target.ReadinessHealthCheckInterval, source.ReadinessHealthCheckInterval, manifest.ReadinessHealthCheckInterval)
+	if source.DockerCredentials == nil && reflect.DeepEqual(manifest.DockerCredentials, target.DockerCredentials) {
+		target.DockerCredentials = nil
+	}
+	if len(source.ServiceBindings) == 0 && reflect.DeepEqual(manifest.ServiceBindings, target.ServiceBindings) {
+		target.ServiceBindings = source.ServiceBindings
+	}
+	if len(source.Sidecars) == 0 && reflect.DeepEqual(manifest.Sidecars, target.Sidecars) {
+		target.Sidecars = source.Sidecars
+	}
+	if len(source.Processes) == 0 && reflect.DeepEqual(manifest.Processes, target.Processes) {
+		target.Processes = source.Processes
+	}
+}
+
+func maskManifestValue[T attr.Value](target *T, source T, manifest T) {
+	if source.IsNull() && manifest.Equal(*target) {
+		*target = source
+	}
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type datasourceAppLogsType struct {
+	Name        types.String `tfsdk:"name"`
+	Space       types.String `tfsdk:"space_name"`
+	Org         types.String `tfsdk:"org_name"`
+	SourceTypes types.Set    `tfsdk:"source_types"`
+	Limit       types.Int64  `tfsdk:"limit"`
+	Id          types.String `tfsdk:"id"`
+	Logs        []appLogType `tfsdk:"logs"`
+}
+
+type appLogType struct {
+	Timestamp   types.String `tfsdk:"timestamp"`
+	SourceType  types.String `tfsdk:"source_type"`
+	InstanceId  types.String `tfsdk:"instance_id"`
+	MessageType types.String `tfsdk:"message_type"`
+	Message     types.String `tfsdk:"message"`
+}
+
+// Response of the Log Cache read endpoint, only log envelopes are considered.
+type logCacheReadResponse struct {
+	Envelopes struct {
+		Batch []logCacheEnvelope `json:"batch"`
+	} `json:"envelopes"`
+}
+
+type logCacheEnvelope struct {
+	Timestamp  string            `json:"timestamp"`
+	SourceId   string            `json:"source_id"`
+	InstanceId string            `json:"instance_id"`
+	Tags       map[string]string `json:"tags"`
+	Log        *struct {
+		Payload []byte `json:"payload"`
+		Type    string `json:"type"`
+	} `json:"log"`
+}
+
+type appLogEntry struct {
+	Timestamp   time.Time
+	SourceType  string
+	InstanceId  string
+	MessageType string
+	Message     string
+}
+
+// Formats the log entries the way the cf CLI prints them.
+func formatAppLogs(entries []appLogEntry) string {
+	var b strings.Builder
+	for _, entry := range entries {
+		b.WriteString(entry.Timestamp.Format(time.RFC3339) + " [" + entry.SourceType + "/" + entry.InstanceId + "] " + entry.MessageType + " " + entry.Message + "\n")
+	}
+	return b.String()
+}
+
+func mapAppLogsValuesToType(entries []appLogEntry) []appLogType {
+	logs := []appLogType{}
+	for _, entry := range entries {
+		logs = append(logs, appLogType{
+			Timestamp:   types.StringValue(entry.Timestamp.Format(time.RFC3339Nano)),
+			SourceType:  types.StringValue(entry.SourceType),
+			InstanceId:  types.StringValue(entry.InstanceId),
+			MessageType: types.StringValue(entry.MessageType),
+			Message:     types.StringValue(entry.Message),
+		})
+	}
+	return logs
+}
@@ -9,12 +9,19 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
+const (
+	buildpackLifecycle = "buildpack"
+	dockerLifecycle    = "docker"
+	cnbLifecycle       = "cnb"
+)
+
 type buildpackType struct {
 	Name           types.String `tfsdk:"name"`
 	Id             types.String `tfsdk:"id"`
 	Path           types.String `tfsdk:"path"`
 	State          types.String `tfsdk:"state"`
 	Stack          types.String `tfsdk:"stack"`
+	Lifecycle      types.String `tfsdk:"lifecycle_type"`
 	Filename       types.String `tfsdk:"filename"`
 	Position       types.Int64  `tfsdk:"position"`
 	Enabled        types.Bool   `tfsdk:"enabled"`
@@ -26,8 +33,19 @@ type buildpackType struct {
 	SourceCodeHash types.String `tfsdk:"source_code_hash"`
 }
 
+// The cf-client buildpack resource does not model the lifecycle yet, hence it is extended here.
+type buildpackWithLifecycle struct {
+	resource.Buildpack
+	Lifecycle string `json:"lifecycle,omitempty"`
+}
+
+type buildpackCreateOrUpdateWithLifecycle struct {
+	resource.BuildpackCreateOrUpdate
+	Lifecycle *string `json:"lifecycle,omitempty"`
+}
+
 // Sets the terraform struct values from the buildpack resource returned by the cf-client.
-func mapBuildpackValuesToType(ctx context.Context, buildpack *resource.Buildpack) (buildpackType, diag.Diagnostics) {
+func mapBuildpackValuesToType(ctx context.Context, buildpack *buildpackWithLifecycle) (buildpackType, diag.Diagnostics) {
 
 	buildpackType := buildpackType{
 		Name:      types.StringValue(buildpack.Name),
@@ -45,6 +63,12 @@ func mapBuildpackValuesToType(ctx context.Context, buildpack *resource.Buildpack
 	if buildpack.Stack != nil {
 		buildpackType.Stack = types.StringValue(*buildpack.Stack)
 	}
+	// Older Cloud Foundry versions only know classic buildpacks and do not return a lifecycle
+	if buildpack.Lifecycle != "" {
+		buildpackType.Lifecycle = types.StringValue(buildpack.Lifecycle)
+	} else {
+		buildpackType.Lifecycle = types.StringValue(buildpackLifecycle)
+	}
 
 	var diags, diagnostics diag.Diagnostics
 	buildpackType.Labels, diags = mapMetadataValueToType(ctx, buildpack.Metadata.Labels)
@@ -56,7 +80,7 @@ func mapBuildpackValuesToType(ctx context.Context, buildpack *resource.Buildpack
 }
 
 // Sets the buildpack resource values for creation with cf-client from the terraform struct values.
-func (data *buildpackType) mapCreateBuildpackTypeToValues(ctx context.Context) (resource.BuildpackCreateOrUpdate, diag.Diagnostics) {
+func (data *buildpackType) mapCreateBuildpackTypeToValues(ctx context.Context) (buildpackCreateOrUpdateWithLifecycle, diag.Diagnostics) {
 
 	var diagnostics diag.Diagnostics
 	createBuildpack := resource.NewBuildpackCreate(data.Name.ValueString())
@@ -80,7 +104,14 @@ func (data *buildpackType) mapCreateBuildpackTypeToValues(ctx context.Context) (
 	annotationsDiags := data.Annotations.ElementsAs(ctx, &createBuildpack.Metadata.Annotations, false)
 	diagnostics.Append(annotationsDiags...)
 
-	return *createBuildpack, diagnostics
+	create := buildpackCreateOrUpdateWithLifecycle{
+		BuildpackCreateOrUpdate: *createBuildpack,
+	}
+	if !data.Lifecycle.IsNull() && !data.Lifecycle.IsUnknown() {
+		create.Lifecycle = strtostrptr(data.Lifecycle.ValueString())
+	}
+
+	return create, diagnostics
 }
 
 // Sets the buildpack resource values for updation with cf-client from the terraform struct values.
@@ -2,23 +2,68 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
+	"slices"
+	"strings"
 
 	"github.com/SAP/terraform-provider-cloudfoundry/internal/mta"
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 )
 
 type MtarType struct {
-	MtarPath             types.String `tfsdk:"mtar_path"`
-	MtarUrl              types.String `tfsdk:"mtar_url"`
-	ExtensionDescriptors types.Set    `tfsdk:"extension_descriptors"`
-	DeployUrl            types.String `tfsdk:"deploy_url"`
-	Space                types.String `tfsdk:"space"`
-	Mta                  types.Object `tfsdk:"mta"`
-	Namespace            types.String `tfsdk:"namespace"`
-	Id                   types.String `tfsdk:"id"`
-	SourceCodeHash       types.String `tfsdk:"source_code_hash"`
+	MtarPath                   types.String   `tfsdk:"mtar_path"`
+	MtarUrl                    types.String   `tfsdk:"mtar_url"`
+	MtaDirectory               types.String   `tfsdk:"mta_directory"`
+	MtaDirectoryHash           types.String   `tfsdk:"mta_directory_hash"`
+	DeploymentPreview          types.Object   `tfsdk:"deployment_preview"`
+	ExtensionDescriptors       types.Set      `tfsdk:"extension_descriptors"`
+	ExtensionDescriptorContent types.List     `tfsdk:"extension_descriptor_content"`
+	DeployUrl                  types.String   `tfsdk:"deploy_url"`
+	Space                      types.String   `tfsdk:"space"`
+	Mta                        types.Object   `tfsdk:"mta"`
+	Namespace                  types.String   `tfsdk:"namespace"`
+	Id                         types.String   `tfsdk:"id"`
+	SourceCodeHash             types.String   `tfsdk:"source_code_hash"`
+	DeployStrategy             types.String   `tfsdk:"deploy_strategy"`
+	BlueGreen                  *MtaBlueGreen  `tfsdk:"blue_green"`
+	AbortOnTimeout             types.Bool     `tfsdk:"abort_on_timeout"`
+	LogsDirectory              types.String   `tfsdk:"logs_directory"`
+	UploadChunkSize            types.Int64    `tfsdk:"upload_chunk_size"`
+	Modules                    types.Set      `tfsdk:"modules"`
+	Resources                  types.Set      `tfsdk:"resources"`
+	VersionRule                types.String   `tfsdk:"version_rule"`
+	KeepFiles                  types.Bool     `tfsdk:"keep_files"`
+	NoRestartSubscribed        types.Bool     `tfsdk:"no_restart_subscribed_apps"`
+	DeleteServiceKeys          types.Bool     `tfsdk:"delete_service_keys"`
+	DeleteServiceBrokers       types.Bool     `tfsdk:"delete_service_brokers"`
+	AbortOnError               types.Bool     `tfsdk:"abort_on_error"`
+	AdditionalParameters       types.Map      `tfsdk:"additional_parameters"`
+	Undeploy                   *MtaUndeploy   `tfsdk:"undeploy"`
+	Timeouts                   timeouts.Value `tfsdk:"timeouts"`
+}
+
+type MtaUndeploy struct {
+	DeleteServices       types.Bool `tfsdk:"delete_services"`
+	DeleteServiceKeys    types.Bool `tfsdk:"delete_service_keys"`
+	DeleteServiceBrokers types.Bool `tfsdk:"delete_service_brokers"`
+	KeepOnDestroy        types.Bool `tfsdk:"keep_on_destroy"`
+}
+
+type MtaBlueGreen struct {
+	SkipIdleStart      types.Bool         `tfsdk:"skip_idle_start"`
+	ManualConfirmation types.Bool         `tfsdk:"manual_confirmation"`
+	SmokeCheck         *MtaBlueGreenCheck `tfsdk:"smoke_check"`
+}
+
+type MtaBlueGreenCheck struct {
+	Url            types.String `tfsdk:"url"`
+	ExpectedStatus types.Int64  `tfsdk:"expected_status"`
+	ExpectedBody   types.String `tfsdk:"expected_body"`
 }
 
 type MtarDataSourceType struct {
@@ -51,6 +96,22 @@ type MtaModuleType struct {
 	Uris                  types.List   `tfsdk:"uris"`
 }
 
+type MtaDeploymentPreviewType struct {
+	Version         types.String `tfsdk:"version"`
+	DeployedVersion types.String `tfsdk:"deployed_version"`
+	AddedModules    types.List   `tfsdk:"added_modules"`
+	RemovedModules  types.List   `tfsdk:"removed_modules"`
+	CreatedServices types.List   `tfsdk:"created_services"`
+}
+
+var mtaDeploymentPreviewAttributes = map[string]attr.Type{
+	"version":          types.StringType,
+	"deployed_version": types.StringType,
+	"added_modules":    types.ListType{ElemType: types.StringType},
+	"removed_modules":  types.ListType{ElemType: types.StringType},
+	"created_services": types.ListType{ElemType: types.StringType},
+}
+
 var mtaObjType = types.ObjectType{
 	AttrTypes: mtaObjAttributes,
 }
@@ -169,3 +230,188 @@ func mapMtaModuleValuesToType(ctx context.Context, module mta.Module) (MtaModule
 	diagnostics.Append(diags...)
 	return mtaModuleType, diags
 }
+
+// Compares the deployment descriptor with the deployed MTA to preview the changes of a deployment.
+func buildMtaDeploymentPreview(ctx context.Context, descriptor mta.MtaDescriptor, deployed types.Object, modules types.Set) (types.Object, diag.Diagnostics) {
+	var diags, diagnostics diag.Diagnostics
+	preview := MtaDeploymentPreviewType{
+		Version:         types.StringValue(descriptor.Version),
+		DeployedVersion: types.StringNull(),
+	}
+
+	var deployedModules, deployedServices []string
+	if !deployed.IsNull() && !deployed.IsUnknown() {
+		var (
+			mtaType      MtaType
+			metadataType MtaMetadataType
+			moduleTypes  []MtaModuleType
+		)
+		diagnostics.Append(deployed.As(ctx, &mtaType, basetypes.ObjectAsOptions{})...)
+		diagnostics.Append(mtaType.Metadata.As(ctx, &metadataType, basetypes.ObjectAsOptions{})...)
+		diagnostics.Append(mtaType.Modules.ElementsAs(ctx, &moduleTypes, false)...)
+		diagnostics.Append(mtaType.Services.ElementsAs(ctx, &deployedServices, false)...)
+		preview.DeployedVersion = metadataType.Version
+		for _, module := range moduleTypes {
+			deployedModules = append(deployedModules, module.ModuleName.ValueString())
+		}
+	}
+
+	var selectedModules []string
+	if !modules.IsNull() && !modules.IsUnknown() {
+		diagnostics.Append(modules.ElementsAs(ctx, &selectedModules, false)...)
+	}
+	descriptorModules := descriptor.ModuleNames()
+	addedModules, removedModules, createdServices := []string{}, []string{}, []string{}
+	for _, name := range descriptorModules {
+		if selectedModules != nil && !slices.Contains(selectedModules, name) {
+			continue
+		}
+		if !slices.Contains(deployedModules, name) {
+			addedModules = append(addedModules, name)
+		}
+	}
+	// Modules which are not selected for deployment stay as they are
+	if selectedModules == nil {
+		for _, name := range deployedModules {
+			if !slices.Contains(descriptorModules, name) {
+				removedModules = append(removedModules, name)
+			}
+		}
+	}
+	for _, res := range descriptor.Resources {
+		if res.Type != "org.cloudfoundry.managed-service" && res.Type != "org.cloudfoundry.user-provided-service" && !strings.HasPrefix(res.Type, "com.sap.xs.") {
+			continue
+		}
+		serviceName := res.Name
+		if name, ok := res.Parameters["service-name"].(string); ok && name != "" {
+			serviceName = name
+		}
+		if !slices.Contains(deployedServices, serviceName) {
+			createdServices = append(createdServices, serviceName)
+		}
+	}
+
+	preview.AddedModules, diags = types.ListValueFrom(ctx, types.StringType, addedModules)
+	diagnostics.Append(diags...)
+	preview.RemovedModules, diags = types.ListValueFrom(ctx, types.StringType, removedModules)
+	diagnostics.Append(diags...)
+	preview.CreatedServices, diags = types.ListValueFrom(ctx, types.StringType, createdServices)
+	diagnostics.Append(diags...)
+	previewObject, diags := types.ObjectValueFrom(ctx, mtaDeploymentPreviewAttributes, preview)
+	diagnostics.Append(diags...)
+	return previewObject, diagnostics
+}
+
+// Adds the optional deploy operation parameters which are configured.
+func (data *MtarType) addDeployParameters(ctx context.Context, parameters map[string]interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for key, value := range map[string]types.Set{
+		"modulesForDeployment":   data.Modules,
+		"resourcesForDeployment": data.Resources,
+	} {
+		if value.IsNull() {
+			continue
+		}
+		var names []string
+		diags.Append(value.ElementsAs(ctx, &names, false)...)
+		parameters[key] = strings.Join(names, ",")
+	}
+	if !data.VersionRule.IsNull() {
+		parameters["versionRule"] = data.VersionRule.ValueString()
+	}
+	for key, value := range map[string]types.Bool{
+		"keepFiles":               data.KeepFiles,
+		"noRestartSubscribedApps": data.NoRestartSubscribed,
+		"deleteServiceKeys":       data.DeleteServiceKeys,
+		"deleteServiceBrokers":    data.DeleteServiceBrokers,
+		"abortOnError":            data.AbortOnError,
+	} {
+		if !value.IsNull() {
+			parameters[key] = value.ValueBool()
+		}
+	}
+	if !data.AdditionalParameters.IsNull() {
+		var additional map[string]string
+		diags.Append(data.AdditionalParameters.ElementsAs(ctx, &additional, false)...)
+		for key, value := range additional {
+			parameters[key] = value
+		}
+	}
+	return diags
+}
+
+// uploadChunkSize returns the size in bytes above which archives are uploaded in parts.
+func (data *MtarType) uploadChunkSize() int64 {
+	if data.UploadChunkSize.IsNull() || data.UploadChunkSize.IsUnknown() {
+		return mtaDefaultUploadChunkSize << 20
+	}
+	return data.UploadChunkSize.ValueInt64() << 20
+}
+
+var mtaOperationStates = []string{"RUNNING", "FINISHED", "ERROR", "ABORTED", mta.ActionRequiredState}
+
+type datasourceMtaOperationsType struct {
+	Space      types.String       `tfsdk:"space"`
+	MtaId      types.String       `tfsdk:"mta_id"`
+	Namespace  types.String       `tfsdk:"namespace"`
+	States     types.Set          `tfsdk:"states"`
+	Last       types.Int64        `tfsdk:"last"`
+	DeployUrl  types.String       `tfsdk:"deploy_url"`
+	Operations []mtaOperationType `tfsdk:"operations"`
+}
+
+type mtaOperationType struct {
+	Id           types.String         `tfsdk:"id"`
+	ProcessType  types.String         `tfsdk:"process_type"`
+	MtaId        types.String         `tfsdk:"mta_id"`
+	Namespace    types.String         `tfsdk:"namespace"`
+	User         types.String         `tfsdk:"user"`
+	StartedAt    types.String         `tfsdk:"started_at"`
+	EndedAt      types.String         `tfsdk:"ended_at"`
+	State        types.String         `tfsdk:"state"`
+	ErrorType    types.String         `tfsdk:"error_type"`
+	AcquiredLock types.Bool           `tfsdk:"acquired_lock"`
+	Parameters   jsontypes.Normalized `tfsdk:"parameters"`
+}
+
+// mapMtaOperationsValuesToType maps the operations of the deploy service, keeping only those of the given namespace if it is set.
+func mapMtaOperationsValuesToType(operations []mta.Operation, namespace types.String) ([]mtaOperationType, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	mtaOperations := []mtaOperationType{}
+	for _, operation := range operations {
+		if !namespace.IsNull() && operation.Namespace != namespace.ValueString() {
+			continue
+		}
+		mtaOperation := mtaOperationType{
+			Id:           types.StringValue(operation.ProcessId),
+			ProcessType:  types.StringValue(operation.ProcessType),
+			MtaId:        types.StringValue(operation.MtaId),
+			Namespace:    optionalStringValue(operation.Namespace),
+			User:         types.StringValue(operation.User),
+			StartedAt:    optionalStringValue(operation.StartedAt),
+			EndedAt:      optionalStringValue(operation.EndedAt),
+			State:        types.StringValue(operation.State),
+			ErrorType:    optionalStringValue(operation.ErrorType),
+			AcquiredLock: types.BoolValue(operation.AcquiredLock),
+			Parameters:   jsontypes.NewNormalizedNull(),
+		}
+		if len(operation.Parameters) > 0 {
+			parameters, err := json.Marshal(operation.Parameters)
+			if err != nil {
+				diags.AddError("Unable to marshal MTA operation parameters", err.Error())
+				continue
+			}
+			mtaOperation.Parameters = jsontypes.NewNormalizedValue(string(parameters))
+		}
+		mtaOperations = append(mtaOperations, mtaOperation)
+	}
+	return mtaOperations, diags
+}
+
+// optionalStringValue maps fields which the deploy service omits when they are not set.
+func optionalStringValue(value string) types.String {
+	if value == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(value)
+}
@@ -29,6 +29,7 @@ type serviceCredentialBindingType struct {
 	Annotations     types.Map            `tfsdk:"annotations"`
 	CreatedAt       types.String         `tfsdk:"created_at"`
 	UpdatedAt       types.String         `tfsdk:"updated_at"`
+	DeleteMode      types.String         `tfsdk:"delete_mode"`
 }
 
 type serviceCredentialBindingTypeWithCredentials struct {
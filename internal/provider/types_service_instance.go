@@ -1,7 +1,9 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"time"
 
 	cfv3client "github.com/cloudfoundry/go-cfclient/v3/client"
@@ -11,28 +13,36 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 )
 
 type serviceInstanceType struct {
-	Name             types.String         `tfsdk:"name"`
-	ID               types.String         `tfsdk:"id"`
-	Type             types.String         `tfsdk:"type"`
-	Space            types.String         `tfsdk:"space"`
-	ServicePlan      types.String         `tfsdk:"service_plan"`
-	Parameters       jsontypes.Normalized `tfsdk:"parameters"`
-	LastOperation    types.Object         `tfsdk:"last_operation"` //LastOperationType
-	Tags             types.List           `tfsdk:"tags"`
-	DashboardURL     types.String         `tfsdk:"dashboard_url"`
-	Credentials      jsontypes.Normalized `tfsdk:"credentials"`
-	SyslogDrainURL   types.String         `tfsdk:"syslog_drain_url"`
-	RouteServiceURL  types.String         `tfsdk:"route_service_url"`
-	MaintenanceInfo  types.Object         `tfsdk:"maintenance_info"` //maintenanceInfoType
-	UpgradeAvailable types.Bool           `tfsdk:"upgrade_available"`
-	Labels           types.Map            `tfsdk:"labels"`
-	Annotations      types.Map            `tfsdk:"annotations"`
-	CreatedAt        types.String         `tfsdk:"created_at"`
-	UpdatedAt        types.String         `tfsdk:"updated_at"`
-	Timeouts         timeouts.Value       `tfsdk:"timeouts"`
+	Name                types.String         `tfsdk:"name"`
+	ID                  types.String         `tfsdk:"id"`
+	Type                types.String         `tfsdk:"type"`
+	Space               types.String         `tfsdk:"space"`
+	ServicePlan         types.String         `tfsdk:"service_plan"`
+	ServiceOffering     types.String         `tfsdk:"service_offering"`
+	PlanName            types.String         `tfsdk:"plan_name"`
+	ServiceBroker       types.String         `tfsdk:"service_broker"`
+	Parameters          jsontypes.Normalized `tfsdk:"parameters"`
+	IgnoreParameterKeys types.Set            `tfsdk:"ignore_parameter_keys"`
+	MaintenanceUpgrade  types.String         `tfsdk:"maintenance_upgrade"`
+	MaintenanceVersion  types.String         `tfsdk:"maintenance_version"`
+	DeleteMode          types.String         `tfsdk:"delete_mode"`
+	LastOperation       types.Object         `tfsdk:"last_operation"` //LastOperationType
+	Tags                types.List           `tfsdk:"tags"`
+	DashboardURL        types.String         `tfsdk:"dashboard_url"`
+	Credentials         jsontypes.Normalized `tfsdk:"credentials"`
+	SyslogDrainURL      types.String         `tfsdk:"syslog_drain_url"`
+	RouteServiceURL     types.String         `tfsdk:"route_service_url"`
+	MaintenanceInfo     types.Object         `tfsdk:"maintenance_info"` //maintenanceInfoType
+	UpgradeAvailable    types.Bool           `tfsdk:"upgrade_available"`
+	Labels              types.Map            `tfsdk:"labels"`
+	Annotations         types.Map            `tfsdk:"annotations"`
+	CreatedAt           types.String         `tfsdk:"created_at"`
+	UpdatedAt           types.String         `tfsdk:"updated_at"`
+	Timeouts            timeouts.Value       `tfsdk:"timeouts"`
 }
 
 type datasourceServiceInstanceType struct {
@@ -222,6 +232,74 @@ func mapMaintenanceInfo(value resource.ServiceInstanceMaintenanceInfo) maintenan
 
 }
 
+// mergeManagedParameters compares the parameters retrieved from the service broker with the configured ones.
+// The configured value is kept if both are semantically equal after dropping the ignored top-level keys, otherwise the
+// retrieved parameters are returned with the ignored keys taken over from the configuration so that only relevant
+// differences show up as drift.
+func mergeManagedParameters(ctx context.Context, configured jsontypes.Normalized, retrieved json.RawMessage, ignoreKeys []string) (jsontypes.Normalized, diag.Diagnostics) {
+	var configuredParams, retrievedParams map[string]interface{}
+	if err := decodeJSONObject([]byte(configured.ValueString()), &configuredParams); err != nil {
+		return configured, nil
+	}
+	if err := decodeJSONObject(retrieved, &retrievedParams); err != nil || retrievedParams == nil {
+		return configured, nil
+	}
+	for _, key := range ignoreKeys {
+		delete(retrievedParams, key)
+		if value, ok := configuredParams[key]; ok {
+			retrievedParams[key] = value
+		}
+	}
+	merged, err := json.Marshal(retrievedParams)
+	if err != nil {
+		return configured, nil
+	}
+	retrievedValue := jsontypes.NewNormalizedValue(string(merged))
+	equal, diags := configured.StringSemanticEquals(ctx, retrievedValue)
+	if equal || diags.HasError() {
+		return configured, diags
+	}
+	return retrievedValue, diags
+}
+
+func decodeJSONObject(data []byte, v *map[string]interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// copyConfigFrom takes over the attributes which are only known to the configuration and not returned by the API.
+func (data *serviceInstanceType) copyConfigFrom(config serviceInstanceType) {
+	data.ServiceOffering = config.ServiceOffering
+	data.PlanName = config.PlanName
+	data.ServiceBroker = config.ServiceBroker
+	data.IgnoreParameterKeys = config.IgnoreParameterKeys
+	data.MaintenanceUpgrade = config.MaintenanceUpgrade
+	data.MaintenanceVersion = config.MaintenanceVersion
+	data.DeleteMode = config.DeleteMode
+	data.Timeouts = config.Timeouts
+}
+
+// managedServiceInstanceChanged reports whether the plan changes any configurable attribute of a managed service instance.
+func managedServiceInstanceChanged(plan, state serviceInstanceType) bool {
+	return !plan.Name.Equal(state.Name) ||
+		!plan.ServicePlan.Equal(state.ServicePlan) ||
+		!plan.Parameters.Equal(state.Parameters) ||
+		!plan.Tags.Equal(state.Tags) ||
+		!plan.Labels.Equal(state.Labels) ||
+		!plan.Annotations.Equal(state.Annotations)
+}
+
+// maintenanceInfoVersion returns the maintenance_info version of the service instance or an empty string if it has none.
+func (data serviceInstanceType) maintenanceInfoVersion(ctx context.Context) (string, diag.Diagnostics) {
+	if data.MaintenanceInfo.IsNull() || data.MaintenanceInfo.IsUnknown() {
+		return "", nil
+	}
+	var maintenanceInfo maintenanceInfoType
+	diags := data.MaintenanceInfo.As(ctx, &maintenanceInfo, basetypes.ObjectAsOptions{})
+	return maintenanceInfo.Version.ValueString(), diags
+}
+
 // isServiceInstanceUpgradable checks if the service instance is upgradable
 // some service instances may not be upgradable.
 func isServiceInstanceUpgradable(ctx context.Context, guid string, c cfv3client.Client) (bool, error) {
@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	cfv3resource "github.com/cloudfoundry/go-cfclient/v3/resource"
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	serviceKeyRotationDefaultGenerations = 2
+)
+
+type serviceKeyRotationType struct {
+	ID              types.String         `tfsdk:"id"`
+	Name            types.String         `tfsdk:"name"`
+	ServiceInstance types.String         `tfsdk:"service_instance"`
+	Parameters      jsontypes.Normalized `tfsdk:"parameters"`
+	Generations     types.Int64          `tfsdk:"generations"`
+	RotationPeriod  types.String         `tfsdk:"rotation_period"`
+	RotationTrigger types.String         `tfsdk:"rotation_trigger"`
+	Overlap         types.String         `tfsdk:"overlap"`
+	Generation      types.Int64          `tfsdk:"generation"`
+	ActiveKeyID     types.String         `tfsdk:"active_key_id"`
+	ActiveKeyName   types.String         `tfsdk:"active_key_name"`
+	Credentials     jsontypes.Normalized `tfsdk:"credentials"`
+	RotatedAt       types.String         `tfsdk:"rotated_at"`
+	Keys            types.List           `tfsdk:"keys"` //serviceKeyType
+}
+
+type serviceKeyType struct {
+	ID         types.String `tfsdk:"id"`
+	Name       types.String `tfsdk:"name"`
+	Generation types.Int64  `tfsdk:"generation"`
+	CreatedAt  types.String `tfsdk:"created_at"`
+}
+
+var serviceKeyAttrTypes = map[string]attr.Type{
+	"id":         types.StringType,
+	"name":       types.StringType,
+	"generation": types.Int64Type,
+	"created_at": types.StringType,
+}
+
+// serviceKey is a generation of a rotated service key as found in Cloud Foundry.
+type serviceKey struct {
+	binding    *cfv3resource.ServiceCredentialBinding
+	generation int64
+}
+
+// generations returns the number of keys to keep.
+func (data serviceKeyRotationType) generations() int64 {
+	if data.Generations.IsNull() || data.Generations.IsUnknown() {
+		return serviceKeyRotationDefaultGenerations
+	}
+	return data.Generations.ValueInt64()
+}
+
+// rotationPeriod returns the configured rotation period or zero if the keys are not rotated periodically.
+func (data serviceKeyRotationType) rotationPeriod() time.Duration {
+	period, _ := time.ParseDuration(data.RotationPeriod.ValueString())
+	return period
+}
+
+// overlap returns the time the newest key must have existed before older keys are deleted.
+func (data serviceKeyRotationType) overlap() time.Duration {
+	overlap, _ := time.ParseDuration(data.Overlap.ValueString())
+	return overlap
+}
+
+// keyName returns the name of the service key of the given generation.
+func (data serviceKeyRotationType) keyName(generation int64) string {
+	return fmt.Sprintf("%s-%d", data.Name.ValueString(), generation)
+}
+
+// rotatedAt returns the creation time of the newest key.
+func (data serviceKeyRotationType) rotatedAt() time.Time {
+	rotatedAt, _ := time.Parse(time.RFC3339, data.RotatedAt.ValueString())
+	return rotatedAt
+}
+
+// rotationDue reports whether the configuration or the age of the newest key requires a new key.
+func (plan serviceKeyRotationType) rotationDue(ctx context.Context, state serviceKeyRotationType, now time.Time) (bool, diag.Diagnostics) {
+	if !plan.RotationTrigger.Equal(state.RotationTrigger) {
+		return true, nil
+	}
+	if plan.Parameters.IsNull() != state.Parameters.IsNull() {
+		return true, nil
+	}
+	var diags diag.Diagnostics
+	if !plan.Parameters.IsNull() {
+		parametersEqual, equalDiags := state.Parameters.StringSemanticEquals(ctx, plan.Parameters)
+		diags.Append(equalDiags...)
+		if !parametersEqual {
+			return true, diags
+		}
+	}
+	period := plan.rotationPeriod()
+	return period > 0 && !now.Before(state.rotatedAt().Add(period)), diags
+}
+
+// filterServiceKeys returns the generations of the rotated key among the given service keys, oldest first.
+func filterServiceKeys(name string, bindings []*cfv3resource.ServiceCredentialBinding) []serviceKey {
+	keyName := regexp.MustCompile(`^` + regexp.QuoteMeta(name) + `-(\d+)$`)
+	keys := []serviceKey{}
+	for _, binding := range bindings {
+		if binding.Name == nil {
+			continue
+		}
+		match := keyName.FindStringSubmatch(*binding.Name)
+		if match == nil {
+			continue
+		}
+		generation, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, serviceKey{binding: binding, generation: generation})
+	}
+	slices.SortFunc(keys, func(a, b serviceKey) int {
+		return cmp.Compare(a.generation, b.generation)
+	})
+	return keys
+}
+
+// mapServiceKeysToType sets the computed attributes from the service keys, whose newest generation is the active key.
+func (data *serviceKeyRotationType) mapServiceKeysToType(ctx context.Context, keys []serviceKey, credentials *cfv3resource.ServiceCredentialBindingDetails) diag.Diagnostics {
+	var diags diag.Diagnostics
+	keyValues := make([]serviceKeyType, 0, len(keys))
+	for _, key := range keys {
+		keyValues = append(keyValues, serviceKeyType{
+			ID:         types.StringValue(key.binding.GUID),
+			Name:       types.StringValue(*key.binding.Name),
+			Generation: types.Int64Value(key.generation),
+			CreatedAt:  types.StringValue(key.binding.CreatedAt.Format(time.RFC3339)),
+		})
+	}
+	data.Keys, diags = types.ListValueFrom(ctx, types.ObjectType{AttrTypes: serviceKeyAttrTypes}, keyValues)
+
+	active := keys[len(keys)-1]
+	data.ID = types.StringValue(data.ServiceInstance.ValueString() + "/" + data.Name.ValueString())
+	data.Generation = types.Int64Value(active.generation)
+	data.ActiveKeyID = types.StringValue(active.binding.GUID)
+	data.ActiveKeyName = types.StringValue(*active.binding.Name)
+	data.RotatedAt = types.StringValue(active.binding.CreatedAt.Format(time.RFC3339))
+	data.Credentials = jsontypes.NewNormalizedNull()
+	if credentials != nil {
+		credentialsJSON, err := json.Marshal(credentials.Credentials)
+		if err != nil {
+			diags.AddError("Unable to encode credentials", "Unable to encode the credentials of service key "+*active.binding.Name+": "+err.Error())
+		} else {
+			data.Credentials = jsontypes.NewNormalizedValue(string(credentialsJSON))
+		}
+	}
+	return diags
+}
@@ -1,16 +1,27 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/SAP/terraform-provider-cloudfoundry/internal/mta"
 	cfv3client "github.com/cloudfoundry/go-cfclient/v3/client"
 	cfv3resource "github.com/cloudfoundry/go-cfclient/v3/resource"
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
 	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
@@ -18,7 +29,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/samber/lo"
+	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
 const (
@@ -177,6 +190,375 @@ func pollJob(ctx context.Context, client cfv3client.Client, jobID string, timeou
 	})
 }
 
+const (
+	deleteModeNormal  = "normal"
+	deleteModePurge   = "purge"
+	deleteModeAbandon = "abandon"
+)
+
+var deleteModes = []string{deleteModeNormal, deleteModePurge, deleteModeAbandon}
+
+// resolveDeleteMode returns the delete mode configured on the resource, or else the default of the provider.
+func resolveDeleteMode(configured types.String, providerDefault string) string {
+	if !configured.IsNull() && !configured.IsUnknown() {
+		return configured.ValueString()
+	}
+	if providerDefault != "" {
+		return providerDefault
+	}
+	return deleteModeNormal
+}
+
+// Key of the resource private state under which an asynchronous job is recorded until it has finished,
+// so that a later plan or apply can pick it up instead of starting the operation again.
+const pendingJobKey = "pending_job"
+
+const (
+	jobOperationCreate = "create"
+	jobOperationUpdate = "update"
+	jobOperationDelete = "delete"
+)
+
+type pendingJob struct {
+	GUID      string `json:"guid"`
+	Operation string `json:"operation"`
+	Failed    bool   `json:"failed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// privateState is implemented by the private state data of all resource requests and responses.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+func getPendingJob(ctx context.Context, private privateState) (*pendingJob, diag.Diagnostics) {
+	data, diags := private.GetKey(ctx, pendingJobKey)
+	if diags.HasError() || len(data) == 0 {
+		return nil, diags
+	}
+	var job pendingJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		diags.AddWarning("Invalid private state", "Unable to decode the pending job of the resource, it is ignored: "+err.Error())
+		return nil, diags
+	}
+	return &job, diags
+}
+
+// setPendingJob records the job in the private state or removes the record if job is nil.
+func setPendingJob(ctx context.Context, private privateState, job *pendingJob) diag.Diagnostics {
+	if job == nil {
+		return private.SetKey(ctx, pendingJobKey, nil)
+	}
+	data, err := json.Marshal(job)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Unable to record pending job", err.Error())
+		return diags
+	}
+	return private.SetKey(ctx, pendingJobKey, data)
+}
+
+// isJobTimeout reports whether the job did not finish within the timeout and may still complete.
+func isJobTimeout(err error) bool {
+	return errors.Is(err, cfv3client.AsyncProcessTimeoutError)
+}
+
+// awaitJob records the job in the private state and polls it like pollJob. The record is removed once the job
+// completed and marked as failed if the job failed. It is kept as is if the job could not be polled to the end,
+// e.g. because of the timeout, so that the next apply resumes polling the same job.
+func awaitJob(ctx context.Context, client cfv3client.Client, private privateState, job pendingJob, timeout time.Duration, diags *diag.Diagnostics) error {
+	diags.Append(setPendingJob(ctx, private, &job)...)
+
+	var state cfv3resource.JobState
+	err := cfv3client.PollForStateOrTimeout(func() (string, error) {
+		j, err := client.Jobs.Get(ctx, job.GUID)
+		if j != nil {
+			state = j.State
+			return string(j.State), err
+		}
+		return "", err
+	}, string(cfv3resource.JobStateComplete), &cfv3client.PollingOptions{
+		Timeout:       timeout,
+		CheckInterval: time.Second * 2,
+		FailedState:   string(cfv3resource.JobStateFailed),
+	})
+
+	switch {
+	case err == nil:
+		diags.Append(setPendingJob(ctx, private, nil)...)
+	case state == cfv3resource.JobStateFailed:
+		if j, _ := client.Jobs.Get(ctx, job.GUID); j != nil && len(j.Errors) > 0 {
+			err = j.Errors[0]
+		}
+		job.Failed = true
+		job.Error = err.Error()
+		diags.Append(setPendingJob(ctx, private, &job)...)
+	}
+	return err
+}
+
+// refreshPendingJob checks the state of a job recorded in the private state without waiting for it,
+// so that finished jobs are no longer picked up by the next plan and failed jobs are surfaced.
+func refreshPendingJob(ctx context.Context, client cfv3client.Client, private privateState, diags *diag.Diagnostics) {
+	job, getDiags := getPendingJob(ctx, private)
+	diags.Append(getDiags...)
+	if job == nil || job.Failed {
+		return
+	}
+	j, err := client.Jobs.Get(ctx, job.GUID)
+	switch {
+	case cfv3resource.IsResourceNotFoundError(err):
+		diags.Append(setPendingJob(ctx, private, nil)...)
+	case err != nil:
+		tflog.Warn(ctx, "unable to refresh pending job", map[string]interface{}{
+			"job":   job.GUID,
+			"error": err.Error(),
+		})
+	case j.State == cfv3resource.JobStateComplete:
+		diags.Append(setPendingJob(ctx, private, nil)...)
+	case j.State == cfv3resource.JobStateFailed:
+		job.Failed = true
+		job.Error = "job " + job.GUID + " failed"
+		if len(j.Errors) > 0 {
+			job.Error = j.Errors[0].Error()
+		}
+		diags.Append(setPendingJob(ctx, private, job)...)
+	}
+}
+
+// planPendingJob makes sure that the next apply picks up a create or update job recorded in the private state.
+// The attribute at forcePath is marked as unknown so that an update is planned, which resumes polling the job.
+// Resources whose creation failed are planned for replacement instead. The private state of the request is passed
+// separately as it cannot be constructed outside of the framework.
+func planPendingJob(ctx context.Context, private privateState, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, forcePath path.Path, unknown attr.Value) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+	job, diags := getPendingJob(ctx, private)
+	resp.Diagnostics.Append(diags...)
+	if job == nil || job.Operation == jobOperationDelete || resp.RequiresReplace.Contains(forcePath) {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, forcePath, unknown)...)
+	switch {
+	case job.Failed && job.Operation == jobOperationCreate:
+		resp.RequiresReplace = append(resp.RequiresReplace, forcePath)
+		resp.Diagnostics.AddWarning(
+			"Failed creation",
+			"The resource is replaced because its creation failed: "+job.Error,
+		)
+	case job.Failed:
+		resp.Diagnostics.AddWarning(
+			"Failed "+job.Operation,
+			"The "+job.Operation+" of the resource is retried because it failed: "+job.Error,
+		)
+	default:
+		resp.Diagnostics.AddWarning(
+			"Pending "+job.Operation,
+			"The "+job.Operation+" of the resource has not finished yet and is awaited during the apply.",
+		)
+	}
+}
+
+// replaceFailedCreation plans the replacement of a service instance or binding whose last operation is a failed creation.
+func replaceFailedCreation(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+	var lastOperationValue types.Object
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("last_operation"), &lastOperationValue)...)
+	if lastOperationValue.IsNull() || lastOperationValue.IsUnknown() {
+		return
+	}
+	var lastOperation lastOperationType
+	resp.Diagnostics.Append(lastOperationValue.As(ctx, &lastOperation, basetypes.ObjectAsOptions{})...)
+	if lastOperation.Type.ValueString() != jobOperationCreate || lastOperation.State.ValueString() != "failed" {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("last_operation"), types.ObjectUnknown(lastOperationAttrTypes))...)
+	resp.RequiresReplace = append(resp.RequiresReplace, path.Root("last_operation"))
+	resp.Diagnostics.AddWarning(
+		"Failed creation",
+		"The resource is replaced because its creation failed: "+lastOperation.Description.ValueString(),
+	)
+}
+
+// resumePendingJob waits for a create or update job recorded in the private state before the resource is updated.
+// It reports whether the job was resumed and adds an error if the job did not complete.
+func resumePendingJob(ctx context.Context, client cfv3client.Client, req resource.UpdateRequest, resp *resource.UpdateResponse, timeout time.Duration) (bool, error) {
+	job, diags := getPendingJob(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if job == nil || job.Operation == jobOperationDelete {
+		return false, nil
+	}
+	if job.Failed {
+		resp.Diagnostics.Append(setPendingJob(ctx, resp.Private, nil)...)
+		return false, nil
+	}
+	if err := awaitJob(ctx, client, resp.Private, *job, timeout, &resp.Diagnostics); err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to resume pending "+job.Operation,
+			"The pending "+job.Operation+" with job "+job.GUID+" did not complete: "+err.Error(),
+		)
+		resp.State.Raw = req.State.Raw
+		return true, err
+	}
+	return true, nil
+}
+
+// pendingDeleteJob returns the job of a deletion recorded in the private state which has not finished yet.
+func pendingDeleteJob(ctx context.Context, private privateState, diags *diag.Diagnostics) string {
+	job, getDiags := getPendingJob(ctx, private)
+	diags.Append(getDiags...)
+	if job == nil || job.Operation != jobOperationDelete || job.Failed {
+		return ""
+	}
+	return job.GUID
+}
+
+// awaitPendingChange waits for a create or update job recorded in the private state before the resource is deleted,
+// e.g. when a creation which exceeded its timeout is replaced. Failed jobs are not awaited, as the resource is deleted
+// anyway, only a job which still did not finish is returned as error.
+func awaitPendingChange(ctx context.Context, client cfv3client.Client, private privateState, timeout time.Duration, diags *diag.Diagnostics) error {
+	job, getDiags := getPendingJob(ctx, private)
+	diags.Append(getDiags...)
+	if job == nil || job.Operation == jobOperationDelete || job.Failed {
+		return nil
+	}
+	if err := awaitJob(ctx, client, private, *job, timeout, diags); isJobTimeout(err) {
+		return err
+	}
+	return nil
+}
+
+// Sends a request to the Cloud Foundry API for payloads not yet modelled by the cf-client and decodes the JSON response into result if given.
+func cfRawRequest(ctx context.Context, client *cfv3client.Client, method string, urlPath string, body any, result any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, client.ApiURL(urlPath), reqBody)
+	if err != nil {
+		return fmt.Errorf("creating %s request for %s failed: %w", method, urlPath, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.ExecuteAuthRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// Sleeps for the given duration unless the context is cancelled before.
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Looks up the GUID of an app by its name, space and organization.
+func findAppGUID(ctx context.Context, client *cfv3client.Client, orgName string, spaceName string, appName string) (string, error) {
+	space, err := findSpace(ctx, client, orgName, spaceName)
+	if err != nil {
+		return "", err
+	}
+	appOpts := cfv3client.NewAppListOptions()
+	appOpts.Names.EqualTo(appName)
+	appOpts.SpaceGUIDs.EqualTo(space.GUID)
+	app, err := client.Applications.Single(ctx, appOpts)
+	if err != nil {
+		return "", fmt.Errorf("could not find app %s: %w", appName, err)
+	}
+	return app.GUID, nil
+}
+
+// Looks up a space by its name and the name of its org.
+func findSpace(ctx context.Context, client *cfv3client.Client, orgName string, spaceName string) (*cfv3resource.Space, error) {
+	orgOpts := cfv3client.NewOrganizationListOptions()
+	orgOpts.Names.EqualTo(orgName)
+	org, err := client.Organizations.Single(ctx, orgOpts)
+	if err != nil {
+		return nil, fmt.Errorf("could not find org %s: %w", orgName, err)
+	}
+	spaceOpts := cfv3client.NewSpaceListOptions()
+	spaceOpts.Names.EqualTo(spaceName)
+	spaceOpts.OrganizationGUIDs.EqualTo(org.GUID)
+	space, err := client.Spaces.Single(ctx, spaceOpts)
+	if err != nil {
+		return nil, fmt.Errorf("could not find space %s: %w", spaceName, err)
+	}
+	return space, nil
+}
+
+// Reads the most recent log envelopes of an app from the Log Cache API announced in the root info.
+// Only logs whose source type starts with one of sourceTypes are returned, oldest first and at most limit entries.
+func readRecentAppLogs(ctx context.Context, client *cfv3client.Client, appGUID string, sourceTypes []string, limit int) ([]appLogEntry, error) {
+	root, err := client.Root.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to discover the log cache endpoint: %w", err)
+	}
+	if root.Links.LogCache.Href == "" {
+		return nil, fmt.Errorf("the Cloud Foundry API does not announce a log cache endpoint")
+	}
+	query := url.Values{}
+	query.Set("envelope_types", "LOG")
+	query.Set("descending", "true")
+	query.Set("limit", "1000")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(root.Links.LogCache.Href, "/")+"/api/v1/read/"+appGUID+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.ExecuteAuthRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read logs from log cache: %w", err)
+	}
+	defer resp.Body.Close()
+	var logs logCacheReadResponse
+	if err = json.NewDecoder(resp.Body).Decode(&logs); err != nil {
+		return nil, fmt.Errorf("unable to decode log cache response: %w", err)
+	}
+
+	// envelopes are returned newest first
+	var entries []appLogEntry
+	for _, envelope := range logs.Envelopes.Batch {
+		if envelope.Log == nil || len(entries) >= limit {
+			continue
+		}
+		sourceType := envelope.Tags["source_type"]
+		if len(sourceTypes) > 0 && !lo.SomeBy(sourceTypes, func(t string) bool { return strings.HasPrefix(sourceType, t) }) {
+			continue
+		}
+		var ts time.Time
+		if nanos, err := strconv.ParseInt(envelope.Timestamp, 10, 64); err == nil {
+			ts = time.Unix(0, nanos).UTC()
+		}
+		entries = append(entries, appLogEntry{
+			Timestamp:   ts,
+			SourceType:  sourceType,
+			InstanceId:  envelope.InstanceId,
+			MessageType: envelope.Log.Type,
+			Message:     strings.TrimRight(string(envelope.Log.Payload), "\n"),
+		})
+	}
+	return lo.Reverse(entries), nil
+}
+
 func mapMetadataValueToType(ctx context.Context, generic map[string]*string) (basetypes.MapValue, diag.Diagnostics) {
 
 	var out basetypes.MapValue
@@ -260,3 +642,76 @@ func copyFields(dst, src interface{}) {
 		}
 	}
 }
+
+// mtaClientForDeployUrl returns a client for the deploy service configured on the resource or
+// the default client of the provider, without changing the shared client. The default client
+// can only be used if the URL of the deploy service could be determined for it.
+func mtaClientForDeployUrl(client *mta.APIClient, clientErr error, deployUrl types.String) (*mta.APIClient, error) {
+	if deployUrl.IsNull() || deployUrl.IsUnknown() {
+		return client, clientErr
+	}
+	return client.WithBasePath(deployUrl.ValueString()), nil
+}
+
+// validateServiceParameters validates the parameters of a service instance or binding against the JSON schema
+// published by the service plan. Validation is skipped if the plan does not publish a schema for them or if the
+// parameters are not known yet.
+func validateServiceParameters(schemaDefinition *json.RawMessage, parameters jsontypes.Normalized, attributePath path.Path, diags *diag.Diagnostics) {
+	if parameters.IsNull() || parameters.IsUnknown() {
+		return
+	}
+	if schemaDefinition == nil || len(*schemaDefinition) == 0 || string(*schemaDefinition) == "null" || string(*schemaDefinition) == "{}" {
+		return
+	}
+	compiler := jsonschema.NewCompiler()
+	// Schemas of service brokers must be self-contained, referenced documents are not loaded.
+	compiler.LoadURL = func(s string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("loading %s is not supported", s)
+	}
+	if err := compiler.AddResource("parameters.json", bytes.NewReader(*schemaDefinition)); err != nil {
+		diags.AddAttributeWarning(attributePath, "Unable to validate parameters", "The schema of the service plan could not be read: "+err.Error())
+		return
+	}
+	parametersSchema, err := compiler.Compile("parameters.json")
+	if err != nil {
+		diags.AddAttributeWarning(attributePath, "Unable to validate parameters", "The schema of the service plan could not be compiled: "+err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(parameters.ValueString()))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		diags.AddAttributeError(attributePath, "Invalid parameters", "Parameters are not valid JSON: "+err.Error())
+		return
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if err := parametersSchema.Validate(value); errors.As(err, &validationErr) {
+		for _, cause := range leafValidationErrors(validationErr) {
+			location := cause.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+			diags.AddAttributeError(
+				attributePath,
+				"Invalid parameters",
+				fmt.Sprintf("The parameters do not match the schema of the service plan at %s: %s", location, cause.Message),
+			)
+		}
+	} else if err != nil {
+		diags.AddAttributeWarning(attributePath, "Unable to validate parameters", err.Error())
+	}
+}
+
+// leafValidationErrors returns the innermost causes of a validation error, which describe the actual violations.
+func leafValidationErrors(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		leaves = append(leaves, leafValidationErrors(cause)...)
+	}
+	return leaves
+}